@@ -0,0 +1,318 @@
+package null
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrTypeMismatch is reported (wrapped in a *FieldError) when a patch field's
+// type cannot be stored in its destination field.
+var ErrTypeMismatch = errors.New("type mismatch")
+
+// FieldError reports a problem with a single field while applying or
+// diffing a patch. Path is the dot-separated patch field path, e.g.
+// "Address.City".
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+// Error implements error.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("null: field %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// prefixError qualifies err with the parent field name.
+func prefixError(name string, err error) error {
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		return &FieldError{Path: name + "." + fe.Path, Err: fe.Err}
+	}
+	return &FieldError{Path: name, Err: err}
+}
+
+// Apply copies the set fields of patch into dst.
+//
+// dst must be a non-nil pointer to a struct. patch must be a struct, or a
+// pointer to one, whose fields are Value[T]s or nested patch structs. Each
+// patch field is matched to the dst field with the same name, or with the
+// name given in its `null:"Name"` tag; `null:"-"` excludes a field.
+//
+// For every Value field:
+//   - Unset leaves the destination untouched.
+//   - Null clears the destination: pointers become nil, Values become Null
+//     and anything else is set to its zero value.
+//   - Valid stores the value. T must be assignable to the destination, to
+//     its element type when it is a pointer, or to U when it is a Value[U].
+//
+// Nested patch structs are applied recursively; a nil destination pointer is
+// only allocated when the nested patch changes something. Type mismatches
+// are reported as a *FieldError wrapping ErrTypeMismatch.
+//
+// Field plans are computed once per (dst, patch) type pair and cached.
+func Apply(dst, patch any) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("null: Apply destination must be a non-nil pointer to a struct, got %T", dst)
+	}
+	pv := reflect.ValueOf(patch)
+	if pv.Kind() == reflect.Pointer {
+		if pv.IsNil() {
+			return nil
+		}
+		pv = pv.Elem()
+	}
+	if pv.Kind() != reflect.Struct {
+		return fmt.Errorf("null: Apply patch must be a struct, got %T", patch)
+	}
+
+	p, err := planFor(dv.Elem().Type(), pv.Type())
+	if err != nil {
+		return err
+	}
+	_, err = p.apply(dv.Elem(), pv)
+	return err
+}
+
+// --- Plans ---
+
+// dstKind describes how a destination field receives a patch value.
+type dstKind uint8
+
+const (
+	dstPlain     dstKind = iota // assign T directly, zero on Null
+	dstPtr                      // allocate *T, nil on Null
+	dstValue                    // Value[U], Null on Null
+	dstNested                   // nested struct, applied recursively
+	dstNestedPtr                // pointer to nested struct
+)
+
+type planField struct {
+	name   string
+	patch  int
+	dst    []int
+	kind   dstKind
+	nested *plan // for dstNested and dstNestedPtr
+	ptr    bool  // nested patch field is a pointer
+}
+
+type plan struct {
+	fields []planField
+}
+
+type planKey struct {
+	dst, patch reflect.Type
+}
+
+var plans sync.Map // planKey -> *plan
+
+// planFor returns the cached plan for applying patch type pt onto dst type dt.
+func planFor(dt, pt reflect.Type) (*plan, error) {
+	if p, ok := plans.Load(planKey{dt, pt}); ok {
+		return p.(*plan), nil
+	}
+	building := make(map[planKey]*plan)
+	p, err := buildPlan(dt, pt, building)
+	if err != nil {
+		return nil, err
+	}
+	for k, bp := range building {
+		plans.LoadOrStore(k, bp)
+	}
+	return p, nil
+}
+
+func buildPlan(dt, pt reflect.Type, building map[planKey]*plan) (*plan, error) {
+	key := planKey{dt, pt}
+	if p, ok := building[key]; ok {
+		return p, nil
+	}
+	if p, ok := plans.Load(key); ok {
+		return p.(*plan), nil
+	}
+
+	p := &plan{}
+	building[key] = p
+
+	for i := range pt.NumField() {
+		sf := pt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("null"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		df, ok := dt.FieldByName(name)
+		if !ok || !df.IsExported() {
+			return nil, &FieldError{Path: sf.Name, Err: fmt.Errorf("no field %s in %s", name, dt)}
+		}
+
+		f, err := buildField(sf, df, building)
+		if err != nil {
+			return nil, err
+		}
+		f.patch = i
+		f.dst = df.Index
+		p.fields = append(p.fields, f)
+	}
+	return p, nil
+}
+
+func buildField(sf, df reflect.StructField, building map[planKey]*plan) (planField, error) {
+	f := planField{name: sf.Name}
+	mismatch := func(from reflect.Type) error {
+		return &FieldError{
+			Path: sf.Name,
+			Err:  fmt.Errorf("%w: cannot assign %s to %s", ErrTypeMismatch, from, df.Type),
+		}
+	}
+
+	if isValueType(sf.Type) {
+		t := valueElem(sf.Type)
+		switch {
+		case isValueType(df.Type):
+			if !t.AssignableTo(valueElem(df.Type)) {
+				return f, mismatch(t)
+			}
+			f.kind = dstValue
+		case t.AssignableTo(df.Type):
+			f.kind = dstPlain
+		case df.Type.Kind() == reflect.Pointer && t.AssignableTo(df.Type.Elem()):
+			f.kind = dstPtr
+		default:
+			return f, mismatch(t)
+		}
+		return f, nil
+	}
+
+	st := sf.Type
+	if st.Kind() == reflect.Pointer {
+		st = st.Elem()
+		f.ptr = true
+	}
+	if st.Kind() != reflect.Struct {
+		return f, &FieldError{Path: sf.Name, Err: fmt.Errorf("unsupported patch field type %s", sf.Type)}
+	}
+
+	dt := df.Type
+	f.kind = dstNested
+	if dt.Kind() == reflect.Pointer {
+		dt = dt.Elem()
+		f.kind = dstNestedPtr
+	}
+	if dt.Kind() != reflect.Struct {
+		return f, mismatch(sf.Type)
+	}
+	nested, err := buildPlan(dt, st, building)
+	if err != nil {
+		return f, prefixError(sf.Name, err)
+	}
+	f.nested = nested
+	return f, nil
+}
+
+// --- Applying ---
+
+// apply applies pv onto dv and reports whether anything changed.
+func (p *plan) apply(dv, pv reflect.Value) (bool, error) {
+	changed := false
+	for i := range p.fields {
+		f := &p.fields[i]
+		src := pv.Field(f.patch)
+
+		var c bool
+		var err error
+		switch f.kind {
+		case dstNested, dstNestedPtr:
+			c, err = f.applyNested(dv, src)
+		default:
+			c, err = f.applyValue(dv, src)
+		}
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || c
+	}
+	return changed, nil
+}
+
+func (f *planField) applyValue(dv, src reflect.Value) (bool, error) {
+	av := src.Interface().(anyValue)
+	state := av.State()
+	if state == Unset {
+		return false, nil
+	}
+
+	dst, err := dv.FieldByIndexErr(f.dst)
+	if err != nil {
+		return false, &FieldError{Path: f.name, Err: err}
+	}
+
+	switch f.kind {
+	case dstValue:
+		dst.Addr().Interface().(anySetter).setReflect(state, av.reflectValue())
+	case dstPtr:
+		if state == Null {
+			dst.SetZero()
+			break
+		}
+		ptr := reflect.New(dst.Type().Elem())
+		ptr.Elem().Set(av.reflectValue())
+		dst.Set(ptr)
+	default:
+		if state == Null {
+			dst.SetZero()
+			break
+		}
+		dst.Set(av.reflectValue())
+	}
+	return true, nil
+}
+
+func (f *planField) applyNested(dv, src reflect.Value) (bool, error) {
+	if f.ptr {
+		if src.IsNil() {
+			return false, nil
+		}
+		src = src.Elem()
+	}
+
+	dst, err := dv.FieldByIndexErr(f.dst)
+	if err != nil {
+		return false, &FieldError{Path: f.name, Err: err}
+	}
+
+	if f.kind == dstNested {
+		changed, err := f.nested.apply(dst, src)
+		if err != nil {
+			return changed, prefixError(f.name, err)
+		}
+		return changed, nil
+	}
+
+	target := dst
+	if dst.IsNil() {
+		target = reflect.New(dst.Type().Elem())
+	}
+	changed, err := f.nested.apply(target.Elem(), src)
+	if err != nil {
+		return changed, prefixError(f.name, err)
+	}
+	if changed && dst.IsNil() {
+		dst.Set(target)
+	}
+	return changed, nil
+}
@@ -0,0 +1,196 @@
+package null
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type applyAddress struct {
+	Street string
+	City   *string
+}
+
+type applyUser struct {
+	Name     string
+	Nickname *string
+	Email    Value[string]
+	Age      int
+	Address  applyAddress
+	Billing  *applyAddress
+	internal string
+}
+
+type applyAddressPatch struct {
+	Street Value[string]
+	City   Value[string]
+}
+
+type applyUserPatch struct {
+	Name     Value[string]
+	Nickname Value[string]
+	Email    Value[string]
+	Years    Value[int] `null:"Age"`
+	Address  applyAddressPatch
+	Billing  *applyAddressPatch
+	Ignored  string `null:"-"`
+}
+
+type ApplySuite struct {
+	suite.Suite
+}
+
+func TestApplySuite(t *testing.T) {
+	suite.Run(t, new(ApplySuite))
+}
+
+func (s *ApplySuite) user() applyUser {
+	nick := "ally"
+	city := "Paris"
+	return applyUser{
+		Name:     "Alice",
+		Nickname: &nick,
+		Email:    New("alice@example.com"),
+		Age:      30,
+		Address:  applyAddress{Street: "Main St", City: &city},
+		internal: "keep",
+	}
+}
+
+func (s *ApplySuite) TestUnsetLeavesFieldsUntouched() {
+	u := s.user()
+	want := s.user()
+	s.Require().NoError(Apply(&u, applyUserPatch{}))
+	s.Equal(want.Name, u.Name)
+	s.Equal(*want.Nickname, *u.Nickname)
+	s.Equal(want.Email, u.Email)
+	s.Equal(want.Age, u.Age)
+	s.Equal(want.Address.Street, u.Address.Street)
+	s.Nil(u.Billing)
+	s.Equal("keep", u.internal)
+}
+
+func (s *ApplySuite) TestValidAssigns() {
+	u := s.user()
+	err := Apply(&u, applyUserPatch{
+		Name:     New("Bob"),
+		Nickname: New("bobby"),
+		Email:    New("bob@example.com"),
+		Years:    New(41),
+	})
+	s.Require().NoError(err)
+	s.Equal("Bob", u.Name)
+	s.Require().NotNil(u.Nickname)
+	s.Equal("bobby", *u.Nickname)
+	s.Equal(New("bob@example.com"), u.Email)
+	s.Equal(41, u.Age)
+}
+
+func (s *ApplySuite) TestNullClears() {
+	u := s.user()
+	err := Apply(&u, &applyUserPatch{
+		Name:     NewNull[string](),
+		Nickname: NewNull[string](),
+		Email:    NewNull[string](),
+		Years:    NewNull[int](),
+	})
+	s.Require().NoError(err)
+	s.Empty(u.Name)
+	s.Nil(u.Nickname)
+	s.True(u.Email.IsNull())
+	s.Zero(u.Age)
+}
+
+func (s *ApplySuite) TestNested() {
+	u := s.user()
+	err := Apply(&u, applyUserPatch{
+		Address: applyAddressPatch{City: NewNull[string]()},
+	})
+	s.Require().NoError(err)
+	s.Equal("Main St", u.Address.Street)
+	s.Nil(u.Address.City)
+}
+
+func (s *ApplySuite) TestNestedPointer_AllocatesOnChange() {
+	u := s.user()
+	err := Apply(&u, applyUserPatch{
+		Billing: &applyAddressPatch{Street: New("Side St")},
+	})
+	s.Require().NoError(err)
+	s.Require().NotNil(u.Billing)
+	s.Equal("Side St", u.Billing.Street)
+}
+
+func (s *ApplySuite) TestNestedPointer_NoAllocationWithoutChange() {
+	u := s.user()
+	s.Require().NoError(Apply(&u, applyUserPatch{Billing: &applyAddressPatch{}}))
+	s.Nil(u.Billing)
+}
+
+func (s *ApplySuite) TestNilPatch() {
+	u := s.user()
+	s.NoError(Apply(&u, (*applyUserPatch)(nil)))
+	s.Equal("Alice", u.Name)
+}
+
+func (s *ApplySuite) TestTypeMismatch() {
+	type dst struct{ Address struct{ City int } }
+	type patch struct{ Address struct{ City Value[string] } }
+
+	err := Apply(&dst{}, patch{})
+	s.Require().Error(err)
+	s.ErrorIs(err, ErrTypeMismatch)
+
+	var fe *FieldError
+	s.Require().ErrorAs(err, &fe)
+	s.Equal("Address.City", fe.Path)
+}
+
+func (s *ApplySuite) TestMissingDestinationField() {
+	type dst struct{ Name string }
+	type patch struct{ Title Value[string] }
+
+	var fe *FieldError
+	s.Require().ErrorAs(Apply(&dst{}, patch{}), &fe)
+	s.Equal("Title", fe.Path)
+}
+
+func (s *ApplySuite) TestUnsupportedPatchField() {
+	type dst struct{ Name string }
+	type patch struct{ Name string }
+	s.Error(Apply(&dst{}, patch{}))
+}
+
+func (s *ApplySuite) TestInvalidArguments() {
+	var u applyUser
+	s.Error(Apply(u, applyUserPatch{}))
+	s.Error(Apply((*applyUser)(nil), applyUserPatch{}))
+	s.Error(Apply(&u, "patch"))
+}
+
+func (s *ApplySuite) TestEmbeddedValueTypes() {
+	type wrapped struct{ Value[string] }
+	type dst struct{ Name wrapped }
+	type patch struct{ Name Value[string] }
+
+	var d dst
+	s.Require().NoError(Apply(&d, patch{Name: New("Alice")}))
+	s.Equal("Alice", d.Name.Get())
+}
+
+func (s *ApplySuite) TestRecursivePatchType() {
+	type node struct {
+		Name string
+		Next *node
+	}
+	type nodePatch struct {
+		Name Value[string]
+		Next *nodePatch
+	}
+
+	n := node{Name: "a"}
+	err := Apply(&n, nodePatch{Next: &nodePatch{Name: New("b")}})
+	s.Require().NoError(err)
+	s.Require().NotNil(n.Next)
+	s.Equal("b", n.Next.Name)
+}
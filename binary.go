@@ -0,0 +1,238 @@
+package null
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// AppendBinary implements encoding.BinaryAppender.
+//
+// The encoding is the state byte, followed for Valid values by the binary
+// form of the value: T's own AppendBinary or MarshalBinary if it has one
+// (time.Time does), big-endian fixed-width integers (8 bytes for int, uint
+// and uintptr) and IEEE 754 floats, a single 0 or 1 byte for bools, and a
+// uvarint length followed by the bytes for strings and []byte. Other types
+// return an error.
+func (v Value[T]) AppendBinary(b []byte) ([]byte, error) {
+	b = append(b, byte(v.state))
+	if v.state != Valid {
+		return b, nil
+	}
+
+	switch x := any(v.v).(type) {
+	case encoding.BinaryAppender:
+		return x.AppendBinary(b)
+	case encoding.BinaryMarshaler:
+		p, err := x.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return append(b, p...), nil
+	}
+
+	rv := reflect.ValueOf(&v.v).Elem()
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendUint(b, uint64(rv.Int()), binaryWidth(rv.Type())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUint(b, rv.Uint(), binaryWidth(rv.Type())), nil
+	case reflect.Float32:
+		return binary.BigEndian.AppendUint32(b, math.Float32bits(float32(rv.Float()))), nil
+	case reflect.Float64:
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(rv.Float())), nil
+	case reflect.Complex64:
+		c := rv.Complex()
+		b = binary.BigEndian.AppendUint32(b, math.Float32bits(float32(real(c))))
+		return binary.BigEndian.AppendUint32(b, math.Float32bits(float32(imag(c)))), nil
+	case reflect.Complex128:
+		c := rv.Complex()
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(real(c)))
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(imag(c))), nil
+	case reflect.String:
+		b = binary.AppendUvarint(b, uint64(rv.Len()))
+		return append(b, rv.String()...), nil
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b = binary.AppendUvarint(b, uint64(rv.Len()))
+			return append(b, rv.Bytes()...), nil
+		}
+	}
+	return nil, fmt.Errorf("null: cannot marshal %s as binary", rv.Type())
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. See AppendBinary for
+// the encoding.
+func (v Value[T]) MarshalBinary() ([]byte, error) {
+	return v.AppendBinary(nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It accepts the
+// output of MarshalBinary; the payload of a Valid value is decoded with
+// T's own UnmarshalBinary if *T has one.
+func (v *Value[T]) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("null: binary: no data")
+	}
+
+	switch state := State(data[0]); state {
+	case Unset, Null:
+		if len(data) != 1 {
+			return fmt.Errorf("null: binary: unexpected payload for %s value", state)
+		}
+		*v = Value[T]{state: state}
+		return nil
+	case Valid:
+		var x T
+		if err := unmarshalBinary(&x, data[1:]); err != nil {
+			return err
+		}
+		*v = New(x)
+		return nil
+	default:
+		return fmt.Errorf("null: binary: invalid state %d", data[0])
+	}
+}
+
+func unmarshalBinary[T any](p *T, data []byte) error {
+	if u, ok := any(p).(encoding.BinaryUnmarshaler); ok {
+		return u.UnmarshalBinary(data)
+	}
+
+	rv := reflect.ValueOf(p).Elem()
+	t := rv.Type()
+	switch rv.Kind() {
+	case reflect.Bool:
+		if len(data) != 1 || data[0] > 1 {
+			return fmt.Errorf("null: binary: invalid bool %x", data)
+		}
+		rv.SetBool(data[0] == 1)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		u, err := readUint(data, binaryWidth(t), t)
+		if err != nil {
+			return err
+		}
+		shift := 64 - 8*binaryWidth(t)
+		i := int64(u<<shift) >> shift
+		if rv.OverflowInt(i) {
+			return fmt.Errorf("null: binary: %d overflows %s", i, t)
+		}
+		rv.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := readUint(data, binaryWidth(t), t)
+		if err != nil {
+			return err
+		}
+		if rv.OverflowUint(u) {
+			return fmt.Errorf("null: binary: %d overflows %s", u, t)
+		}
+		rv.SetUint(u)
+		return nil
+	case reflect.Float32:
+		u, err := readUint(data, 4, t)
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(float64(math.Float32frombits(uint32(u))))
+		return nil
+	case reflect.Float64:
+		u, err := readUint(data, 8, t)
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(math.Float64frombits(u))
+		return nil
+	case reflect.Complex64:
+		u, err := readUint(data, 8, t)
+		if err != nil {
+			return err
+		}
+		re, im := math.Float32frombits(uint32(u>>32)), math.Float32frombits(uint32(u))
+		rv.SetComplex(complex(float64(re), float64(im)))
+		return nil
+	case reflect.Complex128:
+		if len(data) != 16 {
+			return fmt.Errorf("null: binary: %s needs 16 bytes, got %d", t, len(data))
+		}
+		re := math.Float64frombits(binary.BigEndian.Uint64(data))
+		im := math.Float64frombits(binary.BigEndian.Uint64(data[8:]))
+		rv.SetComplex(complex(re, im))
+		return nil
+	case reflect.String:
+		s, err := readBytes(data)
+		if err != nil {
+			return err
+		}
+		rv.SetString(string(s))
+		return nil
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		s, err := readBytes(data)
+		if err != nil {
+			return err
+		}
+		rv.SetBytes(append(make([]byte, 0, len(s)), s...))
+		return nil
+	}
+	return fmt.Errorf("null: cannot unmarshal binary into %s", t)
+}
+
+// binaryWidth returns the encoded size in bytes of the integer type t.
+// int, uint and uintptr always use 8 bytes so the encoding does not depend
+// on the platform.
+func binaryWidth(t reflect.Type) int {
+	switch t.Kind() {
+	case reflect.Int, reflect.Uint, reflect.Uintptr:
+		return 8
+	}
+	return t.Bits() / 8
+}
+
+func appendUint(b []byte, u uint64, width int) []byte {
+	switch width {
+	case 1:
+		return append(b, byte(u))
+	case 2:
+		return binary.BigEndian.AppendUint16(b, uint16(u))
+	case 4:
+		return binary.BigEndian.AppendUint32(b, uint32(u))
+	}
+	return binary.BigEndian.AppendUint64(b, u)
+}
+
+func readUint(data []byte, width int, t reflect.Type) (uint64, error) {
+	if len(data) != width {
+		return 0, fmt.Errorf("null: binary: %s needs %d bytes, got %d", t, width, len(data))
+	}
+	switch width {
+	case 1:
+		return uint64(data[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(data)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(data)), nil
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// readBytes reads a uvarint length followed by exactly that many bytes.
+// Padded lengths are rejected so every value has a single encoding.
+func readBytes(data []byte) ([]byte, error) {
+	n, k := binary.Uvarint(data)
+	if k <= 0 || (k > 1 && data[k-1] == 0) || n != uint64(len(data)-k) {
+		return nil, errors.New("null: binary: invalid length prefix")
+	}
+	return data[k:], nil
+}
@@ -0,0 +1,225 @@
+package null
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	_ encoding.BinaryAppender    = Value[int]{}
+	_ encoding.BinaryMarshaler   = Value[int]{}
+	_ encoding.BinaryUnmarshaler = (*Value[int])(nil)
+)
+
+type level int8
+
+// point is encoded with its own MarshalBinary as "x,y".
+type point struct{ X, Y byte }
+
+func (p point) MarshalBinary() ([]byte, error) {
+	if p.X == 0xff {
+		return nil, errors.New("bad point")
+	}
+	return []byte{p.X, ',', p.Y}, nil
+}
+
+func (p *point) UnmarshalBinary(data []byte) error {
+	if len(data) != 3 || data[1] != ',' {
+		return errors.New("bad point")
+	}
+	p.X, p.Y = data[0], data[2]
+	return nil
+}
+
+func TestMarshalBinary(t *testing.T) {
+	v, s := byte(Valid), byte(Null)
+	tests := map[string]struct {
+		v    encoding.BinaryMarshaler
+		want []byte
+	}{
+		"unset":        {Value[int]{}, []byte{0}},
+		"null":         {NewNull[int](), []byte{s}},
+		"true":         {New(true), []byte{v, 1}},
+		"false":        {New(false), []byte{v, 0}},
+		"int":          {New(-2), []byte{v, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}},
+		"int8":         {New(int8(-1)), []byte{v, 0xff}},
+		"named int8":   {New(level(3)), []byte{v, 3}},
+		"uint16":       {New(uint16(0x0102)), []byte{v, 1, 2}},
+		"int32":        {New(int32(1)), []byte{v, 0, 0, 0, 1}},
+		"uint":         {New(uint(1)), []byte{v, 0, 0, 0, 0, 0, 0, 0, 1}},
+		"float32":      {New(float32(1)), []byte{v, 0x3f, 0x80, 0, 0}},
+		"float64":      {New(1.0), []byte{v, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+		"complex64":    {New(complex64(1i)), []byte{v, 0, 0, 0, 0, 0x3f, 0x80, 0, 0}},
+		"string":       {New("hi"), []byte{v, 2, 'h', 'i'}},
+		"empty string": {New(""), []byte{v, 0}},
+		"bytes":        {New([]byte{9}), []byte{v, 1, 9}},
+		"duration":     {New(time.Duration(1)), []byte{v, 0, 0, 0, 0, 0, 0, 0, 1}},
+		"marshaler":    {New(point{1, 2}), []byte{v, 1, ',', 2}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := tt.v.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b)
+		})
+	}
+}
+
+func TestUnmarshalBinary_Errors(t *testing.T) {
+	v := byte(Valid)
+	tests := map[string]struct {
+		data   []byte
+		decode func([]byte) error
+		want   string
+	}{
+		"empty":         {nil, decodeBinary[int], "no data"},
+		"bad state":     {[]byte{5}, decodeBinary[int], "invalid state 5"},
+		"null payload":  {[]byte{byte(Null), 0}, decodeBinary[int], "unexpected payload"},
+		"short int":     {[]byte{v, 1, 2}, decodeBinary[int], "int needs 8 bytes, got 2"},
+		"long int8":     {[]byte{v, 1, 2}, decodeBinary[int8], "int8 needs 1 bytes, got 2"},
+		"bad bool":      {[]byte{v, 2}, decodeBinary[bool], "invalid bool"},
+		"short float":   {[]byte{v, 1}, decodeBinary[float64], "needs 8 bytes"},
+		"short complex": {[]byte{v, 1}, decodeBinary[complex128], "needs 16 bytes"},
+		"bad length":    {[]byte{v, 3, 'a'}, decodeBinary[string], "invalid length prefix"},
+		"no length":     {[]byte{v}, decodeBinary[[]byte], "invalid length prefix"},
+		"trailing":      {[]byte{v, 1, 'a', 'b'}, decodeBinary[string], "invalid length prefix"},
+		"padded length": {[]byte{v, 0x81, 0, 'a'}, decodeBinary[string], "invalid length prefix"},
+		"unsupported":   {[]byte{v}, decodeBinary[[]int], "cannot unmarshal binary into []int"},
+		"unmarshaler":   {[]byte{v, 1}, decodeBinary[point], "bad point"},
+		"bad time":      {[]byte{v, 0}, decodeBinary[time.Time], "Time.UnmarshalBinary"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.decode(tt.data), tt.want)
+		})
+	}
+}
+
+func decodeBinary[T any](data []byte) error {
+	var v Value[T]
+	return v.UnmarshalBinary(data)
+}
+
+type BinarySuite struct {
+	suite.Suite
+}
+
+func TestBinarySuite(t *testing.T) {
+	suite.Run(t, new(BinarySuite))
+}
+
+func (s *BinarySuite) TestRoundTrip() {
+	binaryRoundTrip(s.T(), Value[string]{})
+	binaryRoundTrip(s.T(), NewNull[string]())
+	binaryRoundTrip(s.T(), New(strings.Repeat("x", 300)))
+	binaryRoundTrip(s.T(), New(int8(math.MinInt8)))
+	binaryRoundTrip(s.T(), New(int16(math.MinInt16)))
+	binaryRoundTrip(s.T(), New(int64(math.MinInt64)))
+	binaryRoundTrip(s.T(), New(uint64(math.MaxUint64)))
+	binaryRoundTrip(s.T(), New(uintptr(7)))
+	binaryRoundTrip(s.T(), New(float32(-1.5)))
+	binaryRoundTrip(s.T(), New(math.Inf(-1)))
+	binaryRoundTrip(s.T(), New(complex(1.5, -2)))
+	binaryRoundTrip(s.T(), New(complex64(3+4i)))
+	binaryRoundTrip(s.T(), New([]byte{}))
+	binaryRoundTrip(s.T(), New(level(-4)))
+	binaryRoundTrip(s.T(), New(point{3, 4}))
+	binaryRoundTrip(s.T(), New(New(true)))
+	binaryRoundTrip(s.T(), New(NewNull[int]()))
+}
+
+func (s *BinarySuite) TestTime() {
+	ts := time.Date(2024, 1, 15, 10, 0, 0, 5, time.FixedZone("", 3600))
+	want, err := ts.MarshalBinary()
+	s.Require().NoError(err)
+
+	b, err := New(ts).MarshalBinary()
+	s.Require().NoError(err)
+	s.Equal(append([]byte{byte(Valid)}, want...), b)
+
+	var v Value[time.Time]
+	s.Require().NoError(v.UnmarshalBinary(b))
+	s.True(ts.Equal(v.Get()))
+}
+
+func (s *BinarySuite) TestAppend() {
+	b, err := New(uint16(1)).AppendBinary([]byte("prefix"))
+	s.Require().NoError(err)
+	s.Equal([]byte{'p', 'r', 'e', 'f', 'i', 'x', byte(Valid), 0, 1}, b)
+}
+
+func (s *BinarySuite) TestAppend_NoAllocs() {
+	buf := make([]byte, 0, 64)
+	v := New(int64(42))
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = v.AppendBinary(buf[:0])
+	})
+	s.Zero(allocs)
+}
+
+func (s *BinarySuite) TestMarshalErrors() {
+	_, err := New([]int{1}).MarshalBinary()
+	s.ErrorContains(err, "null: cannot marshal []int as binary")
+
+	_, err = New(point{X: 0xff}).MarshalBinary()
+	s.ErrorContains(err, "bad point")
+}
+
+func binaryRoundTrip[T any](t *testing.T, in Value[T]) {
+	t.Helper()
+	b, err := in.MarshalBinary()
+	require.NoError(t, err)
+	var out Value[T]
+	require.NoError(t, out.UnmarshalBinary(b))
+	assert.Equal(t, in, out)
+}
+
+func FuzzBinary(f *testing.F) {
+	f.Add(byte(Valid), "héllo", int64(-1), 1.5, []byte{0, 1})
+	f.Add(byte(Null), "", int64(math.MaxInt64), math.Inf(1), []byte(nil))
+	f.Fuzz(func(t *testing.T, sb byte, s string, i int64, fl float64, bs []byte) {
+		state := fuzzState(sb)
+		binaryRoundTrip(t, fuzzValue(state, s))
+		binaryRoundTrip(t, fuzzValue(state, i))
+		binaryRoundTrip(t, fuzzValue(state, int16(i)))
+		binaryRoundTrip(t, fuzzValue(state, uint32(i)))
+		binaryRoundTrip(t, fuzzValue(state, i%2 == 0))
+		binaryRoundTrip(t, fuzzValue(state, time.Duration(i)))
+		if !math.IsNaN(fl) {
+			binaryRoundTrip(t, fuzzValue(state, fl))
+			binaryRoundTrip(t, fuzzValue(state, complex(fl, -fl)))
+		}
+
+		b, err := fuzzValue(state, bs).MarshalBinary()
+		require.NoError(t, err)
+		var out Value[[]byte]
+		require.NoError(t, out.UnmarshalBinary(b))
+		assert.Equal(t, state, out.State())
+		if state == Valid {
+			assert.True(t, bytes.Equal(bs, out.Get()))
+		}
+	})
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	f.Add([]byte{byte(Valid), 2, 'h', 'i'})
+	f.Add([]byte{byte(Null)})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v Value[string]
+		if v.UnmarshalBinary(data) != nil {
+			return
+		}
+		b, err := v.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, data, b)
+	})
+}
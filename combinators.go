@@ -0,0 +1,105 @@
+package null
+
+// Combinators transform Values without unpacking them by hand. Unless noted
+// otherwise they only call their function for Valid inputs and carry the
+// state of the others through unchanged: Unset stays Unset and Null stays
+// Null. When several Values are combined, Unset wins over Null.
+
+// --- Transforming ---
+
+// Map returns f applied to the value of v if v is Valid. Unset and Null
+// Values are returned in the same state with type U.
+func Map[T, U any](v Value[T], f func(T) U) Value[U] {
+	if v.state != Valid {
+		return Value[U]{state: v.state}
+	}
+	return New(f(v.v))
+}
+
+// FlatMap is like Map for functions that themselves return a Value, e.g. a
+// parser that yields Null for an empty string. The result of f is returned
+// as is, so it may be in any state.
+func FlatMap[T, U any](v Value[T], f func(T) Value[U]) Value[U] {
+	if v.state != Valid {
+		return Value[U]{state: v.state}
+	}
+	return f(v.v)
+}
+
+// Filter returns v if it is Valid and keep reports true for its value. A
+// Valid value that keep rejects becomes Null; Unset and Null are returned
+// unchanged.
+func Filter[T any](v Value[T], keep func(T) bool) Value[T] {
+	if v.state == Valid && !keep(v.v) {
+		return NewNull[T]()
+	}
+	return v
+}
+
+// --- Fallbacks ---
+
+// Or returns v if it is Valid and alt otherwise. When alt is Unset, v is
+// returned instead so a Null v is not lost. Or(a, b) is Coalesce(a, b).
+func Or[T any](v, alt Value[T]) Value[T] {
+	if v.state == Valid || alt.state == Unset {
+		return v
+	}
+	return alt
+}
+
+// OrElse is like Or but only calls alt when v is not Valid.
+func OrElse[T any](v Value[T], alt func() Value[T]) Value[T] {
+	if v.state == Valid {
+		return v
+	}
+	return Or(v, alt())
+}
+
+// Coalesce returns the first Valid Value in vs. Without one it returns Null
+// if any of vs is Null and Unset otherwise, like SQL's COALESCE.
+func Coalesce[T any](vs ...Value[T]) Value[T] {
+	var out Value[T]
+	for _, v := range vs {
+		if v.state == Valid {
+			return v
+		}
+		if v.state == Null {
+			out.state = Null
+		}
+	}
+	return out
+}
+
+// --- Combining ---
+
+// Zip2 returns f applied to the values of a and b if both are Valid.
+// Otherwise the result is Unset if either is Unset, and Null if not.
+func Zip2[A, B, R any](a Value[A], b Value[B], f func(A, B) R) Value[R] {
+	if s := combine(a.state, b.state); s != Valid {
+		return Value[R]{state: s}
+	}
+	return New(f(a.v, b.v))
+}
+
+// Zip3 is Zip2 for three Values.
+func Zip3[A, B, C, R any](a Value[A], b Value[B], c Value[C], f func(A, B, C) R) Value[R] {
+	if s := combine(a.state, b.state, c.state); s != Valid {
+		return Value[R]{state: s}
+	}
+	return New(f(a.v, b.v, c.v))
+}
+
+// combine returns the state of several Values combined: Unset if any is
+// Unset, then Null if any is Null, and Valid otherwise.
+func combine(states ...State) State {
+	out := Valid
+	for _, s := range states {
+		if s == Unset {
+			return Unset
+		}
+		if s == Null {
+			out = Null
+		}
+	}
+	return out
+}
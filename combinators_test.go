@@ -0,0 +1,137 @@
+package null
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CombinatorSuite struct {
+	suite.Suite
+}
+
+func TestCombinatorSuite(t *testing.T) {
+	suite.Run(t, new(CombinatorSuite))
+}
+
+func (s *CombinatorSuite) TestMap() {
+	s.Equal(New(5), Map(New("hello"), func(s string) int { return len(s) }))
+	s.Equal(NewNull[int](), Map(NewNull[string](), func(s string) int { return len(s) }))
+	s.Equal(Value[int]{}, Map(Value[string]{}, func(s string) int { return len(s) }))
+}
+
+func (s *CombinatorSuite) TestMap_NotCalledUnlessValid() {
+	called := false
+	f := func(string) int { called = true; return 0 }
+	Map(NewNull[string](), f)
+	Map(Value[string]{}, f)
+	s.False(called)
+}
+
+func (s *CombinatorSuite) TestFlatMap() {
+	parse := func(s string) Value[int] {
+		if s == "" {
+			return NewNull[int]()
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return Value[int]{}
+		}
+		return New(n)
+	}
+
+	s.Equal(New(42), FlatMap(New("42"), parse))
+	s.Equal(NewNull[int](), FlatMap(New(""), parse))
+	s.Equal(Value[int]{}, FlatMap(New("x"), parse))
+	s.Equal(NewNull[int](), FlatMap(NewNull[string](), parse))
+	s.Equal(Value[int]{}, FlatMap(Value[string]{}, parse))
+}
+
+func (s *CombinatorSuite) TestFilter() {
+	nonEmpty := func(s string) bool { return strings.TrimSpace(s) != "" }
+
+	s.Equal(New("a"), Filter(New("a"), nonEmpty))
+	s.Equal(NewNull[string](), Filter(New("  "), nonEmpty))
+	s.Equal(NewNull[string](), Filter(NewNull[string](), nonEmpty))
+	s.Equal(Value[string]{}, Filter(Value[string]{}, nonEmpty))
+}
+
+func (s *CombinatorSuite) TestOrElse_Lazy() {
+	called := false
+	alt := func() Value[int] { called = true; return New(2) }
+
+	s.Equal(New(1), OrElse(New(1), alt))
+	s.False(called)
+
+	s.Equal(New(2), OrElse(NewNull[int](), alt))
+	s.True(called)
+
+	s.Equal(NewNull[int](), OrElse(NewNull[int](), func() Value[int] { return Value[int]{} }))
+}
+
+func (s *CombinatorSuite) TestZip2() {
+	add := func(a int, b string) string { return strconv.Itoa(a) + b }
+
+	s.Equal(New("1a"), Zip2(New(1), New("a"), add))
+	s.Equal(NewNull[string](), Zip2(NewNull[int](), New("a"), add))
+	s.Equal(NewNull[string](), Zip2(NewNull[int](), NewNull[string](), add))
+	s.Equal(Value[string]{}, Zip2(Value[int]{}, New("a"), add))
+	s.Equal(Value[string]{}, Zip2(NewNull[int](), Value[string]{}, add))
+}
+
+func (s *CombinatorSuite) TestZip3() {
+	sum := func(a, b, c int) int { return a + b + c }
+
+	s.Equal(New(6), Zip3(New(1), New(2), New(3), sum))
+	s.Equal(NewNull[int](), Zip3(New(1), NewNull[int](), New(3), sum))
+	s.Equal(Value[int]{}, Zip3(New(1), NewNull[int](), Value[int]{}, sum))
+}
+
+func TestOr(t *testing.T) {
+	var (
+		unset = Value[int]{}
+		null  = NewNull[int]()
+		one   = New(1)
+		two   = New(2)
+	)
+	tests := map[string]struct {
+		v, alt Value[int]
+		want   Value[int]
+	}{
+		"valid wins":            {one, two, one},
+		"valid over null alt":   {one, null, one},
+		"null falls back":       {null, two, two},
+		"unset falls back":      {unset, two, two},
+		"unset then null":       {unset, null, null},
+		"null kept over unset":  {null, unset, null},
+		"unset stays unset":     {unset, unset, unset},
+		"null and null is null": {null, null, null},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Or(tt.v, tt.alt))
+			assert.Equal(t, tt.want, Coalesce(tt.v, tt.alt))
+		})
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	tests := map[string]struct {
+		vs   []Value[string]
+		want Value[string]
+	}{
+		"empty":        {nil, Value[string]{}},
+		"all unset":    {[]Value[string]{{}, {}}, Value[string]{}},
+		"any null":     {[]Value[string]{{}, NewNull[string](), {}}, NewNull[string]()},
+		"first valid":  {[]Value[string]{NewNull[string](), New("a"), New("b")}, New("a")},
+		"empty string": {[]Value[string]{{}, New("")}, New("")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Coalesce(tt.vs...))
+		})
+	}
+}
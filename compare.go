@@ -0,0 +1,112 @@
+package null
+
+import (
+	"cmp"
+	"hash/maphash"
+)
+
+// --- Equality ---
+
+// Equal reports whether a and b are in the same state and, when both are
+// Valid, hold equal values.
+func Equal[T comparable](a, b Value[T]) bool {
+	return EqualFunc(a, b, func(x, y T) bool { return x == y })
+}
+
+// EqualFunc is like Equal but compares Valid values with eq, for element
+// types that are not comparable (such as slices) or need custom equality
+// (such as time.Time.Equal).
+func EqualFunc[T, U any](a Value[T], b Value[U], eq func(T, U) bool) bool {
+	if a.state != b.state {
+		return false
+	}
+	return a.state != Valid || eq(a.v, b.v)
+}
+
+// --- Ordering ---
+
+// CompareOption configures Comparer and ComparerFunc.
+type CompareOption func(*compareConfig)
+
+type compareConfig struct {
+	nullsLast bool
+}
+
+// NullsFirst sorts Unset and Null before every Valid value. This is the
+// default.
+func NullsFirst() CompareOption {
+	return func(c *compareConfig) { c.nullsLast = false }
+}
+
+// NullsLast sorts Unset and Null after every Valid value, Unset still
+// before Null.
+func NullsLast() CompareOption {
+	return func(c *compareConfig) { c.nullsLast = true }
+}
+
+// Compare returns -1, 0 or +1 depending on whether a sorts before, equal
+// to or after b. Values are ordered Unset < Null < Valid, and Valid values
+// by cmp.Compare. Compare can be passed to slices.SortFunc directly.
+func Compare[T cmp.Ordered](a, b Value[T]) int {
+	return CompareFunc(a, b, cmp.Compare[T])
+}
+
+// CompareFunc is like Compare but orders Valid values with f.
+func CompareFunc[T any](a, b Value[T], f func(x, y T) int) int {
+	if a.state == Valid && b.state == Valid {
+		return f(a.v, b.v)
+	}
+	return cmp.Compare(a.state, b.state)
+}
+
+// Comparer returns a comparison function for slices.SortFunc and friends
+// that orders Values like Compare, adjusted by opts:
+//
+//	slices.SortFunc(ages, null.Comparer[int](null.NullsLast()))
+func Comparer[T cmp.Ordered](opts ...CompareOption) func(a, b Value[T]) int {
+	return ComparerFunc(cmp.Compare[T], opts...)
+}
+
+// ComparerFunc is like Comparer but orders Valid values with f.
+func ComparerFunc[T any](f func(x, y T) int, opts ...CompareOption) func(a, b Value[T]) int {
+	var c compareConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if !c.nullsLast {
+		return func(a, b Value[T]) int { return CompareFunc(a, b, f) }
+	}
+	return func(a, b Value[T]) int {
+		if a.state == Valid && b.state == Valid {
+			return f(a.v, b.v)
+		}
+		return cmp.Compare(lastRank(a.state), lastRank(b.state))
+	}
+}
+
+// lastRank ranks states for NullsLast: Valid < Unset < Null.
+func lastRank(s State) int {
+	if s == Valid {
+		return -1
+	}
+	return int(s)
+}
+
+// --- Hashing ---
+
+// WriteHash adds v to h. Values that are Equal write the same bytes, so
+// Values can be combined with other fields into a map key or cache key.
+func WriteHash[T comparable](h *maphash.Hash, v Value[T]) {
+	_ = h.WriteByte(byte(v.state))
+	if v.state == Valid {
+		maphash.WriteComparable(h, v.v)
+	}
+}
+
+// Hash returns a hash of v with the given seed, consistent with Equal.
+func Hash[T comparable](seed maphash.Seed, v Value[T]) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	WriteHash(&h, v)
+	return h.Sum64()
+}
@@ -0,0 +1,130 @@
+package null
+
+import (
+	"hash/maphash"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestEqual(t *testing.T) {
+	tests := map[string]struct {
+		a, b Value[int]
+		want bool
+	}{
+		"unset unset":     {Value[int]{}, Value[int]{}, true},
+		"null null":       {NewNull[int](), NewNull[int](), true},
+		"valid same":      {New(1), New(1), true},
+		"valid different": {New(1), New(2), false},
+		"unset null":      {Value[int]{}, NewNull[int](), false},
+		"null zero":       {NewNull[int](), New(0), false},
+		"unset zero":      {Value[int]{}, New(0), false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Equal(tt.a, tt.b))
+			assert.Equal(t, tt.want, Equal(tt.b, tt.a))
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := map[string]struct {
+		a, b Value[int]
+		want int
+	}{
+		"unset unset":    {Value[int]{}, Value[int]{}, 0},
+		"null null":      {NewNull[int](), NewNull[int](), 0},
+		"unset null":     {Value[int]{}, NewNull[int](), -1},
+		"null valid":     {NewNull[int](), New(-5), -1},
+		"unset valid":    {Value[int]{}, New(-5), -1},
+		"valid less":     {New(1), New(2), -1},
+		"valid equal":    {New(2), New(2), 0},
+		"valid more":     {New(3), New(2), 1},
+		"valid null":     {New(0), NewNull[int](), 1},
+		"null unset":     {NewNull[int](), Value[int]{}, 1},
+		"valid unset":    {New(0), Value[int]{}, 1},
+		"valid negative": {New(-1), New(0), -1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Compare(tt.a, tt.b))
+		})
+	}
+}
+
+type CompareSuite struct {
+	suite.Suite
+}
+
+func TestCompareSuite(t *testing.T) {
+	suite.Run(t, new(CompareSuite))
+}
+
+func (s *CompareSuite) values() []Value[int] {
+	return []Value[int]{New(2), NewNull[int](), {}, New(1), NewNull[int](), New(3)}
+}
+
+func (s *CompareSuite) TestSortFunc() {
+	vs := s.values()
+	slices.SortFunc(vs, Compare[int])
+	s.Equal([]Value[int]{{}, NewNull[int](), NewNull[int](), New(1), New(2), New(3)}, vs)
+}
+
+func (s *CompareSuite) TestComparer_NullsFirst() {
+	vs := s.values()
+	slices.SortFunc(vs, Comparer[int](NullsFirst()))
+	s.Equal([]Value[int]{{}, NewNull[int](), NewNull[int](), New(1), New(2), New(3)}, vs)
+}
+
+func (s *CompareSuite) TestComparer_NullsLast() {
+	vs := s.values()
+	slices.SortFunc(vs, Comparer[int](NullsLast()))
+	s.Equal([]Value[int]{New(1), New(2), New(3), {}, NewNull[int](), NewNull[int]()}, vs)
+}
+
+func (s *CompareSuite) TestComparerFunc() {
+	vs := []Value[string]{New("b"), NewNull[string](), New("A")}
+	slices.SortFunc(vs, ComparerFunc(func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}, NullsLast()))
+	s.Equal([]Value[string]{New("A"), New("b"), NewNull[string]()}, vs)
+}
+
+func (s *CompareSuite) TestEqualFunc() {
+	utc := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	local := utc.In(time.FixedZone("CET", 3600))
+
+	s.True(EqualFunc(New(utc), New(local), time.Time.Equal))
+	s.False(EqualFunc(New(utc), NewNull[time.Time](), time.Time.Equal))
+	s.True(EqualFunc(New([]int{1}), New([]int{1}), slices.Equal[[]int]))
+}
+
+func (s *CompareSuite) TestHash() {
+	seed := maphash.MakeSeed()
+
+	s.Equal(Hash(seed, New("a")), Hash(seed, New("a")))
+	s.Equal(Hash(seed, NewNull[string]()), Hash(seed, NewNull[string]()))
+	s.NotEqual(Hash(seed, New("a")), Hash(seed, New("b")))
+	s.NotEqual(Hash(seed, Value[string]{}), Hash(seed, NewNull[string]()))
+	s.NotEqual(Hash(seed, NewNull[string]()), Hash(seed, New("")))
+}
+
+func (s *CompareSuite) TestWriteHash() {
+	seed := maphash.MakeSeed()
+	key := func(id int, name Value[string]) uint64 {
+		var h maphash.Hash
+		h.SetSeed(seed)
+		maphash.WriteComparable(&h, id)
+		WriteHash(&h, name)
+		return h.Sum64()
+	}
+
+	s.Equal(key(1, New("a")), key(1, New("a")))
+	s.NotEqual(key(1, New("a")), key(2, New("a")))
+	s.NotEqual(key(1, NewNull[string]()), key(1, Value[string]{}))
+}
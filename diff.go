@@ -0,0 +1,219 @@
+package null
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DiffOption configures Diff.
+type DiffOption func(*diffConfig)
+
+type diffConfig struct {
+	equal map[reflect.Type]func(a, b reflect.Value) bool
+}
+
+// WithEqual makes Diff compare values of type T with eq instead of
+// reflect.DeepEqual, e.g. WithEqual(time.Time.Equal).
+func WithEqual[T any](eq func(a, b T) bool) DiffOption {
+	return func(c *diffConfig) {
+		c.equal[reflect.TypeFor[T]()] = func(a, b reflect.Value) bool {
+			return eq(a.Interface().(T), b.Interface().(T))
+		}
+	}
+}
+
+func (c *diffConfig) eq(a, b reflect.Value) bool {
+	if eq, ok := c.equal[a.Type()]; ok {
+		return eq(a, b)
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// Diff compares old and next and fills patch with the changes between them,
+// so that Apply(&old, patch) yields next. The one exception is a Value field
+// that is Unset in next: a patch cannot make a field Unset, so the field is
+// left unchanged and keeps its old state.
+//
+// old and next must be structs (or pointers to structs) of the same type and
+// patch a non-nil pointer to a patch struct. Patch fields are matched to
+// the domain fields exactly as in Apply. Every Value field of the patch is
+// first reset to Unset and then set to:
+//   - Unset when the field did not change or is an Unset Value in next.
+//   - Null when the field of next is a nil pointer, a nil map, slice or
+//     interface, or a Null Value, and the old one was not.
+//   - Valid holding the value from next otherwise.
+//
+// Nested patch structs are diffed recursively; a nil nested patch pointer is
+// only allocated when something changed. A nil nested domain pointer is
+// compared as its zero value.
+func Diff(patch, old, next any, opts ...DiffOption) error {
+	pv := reflect.ValueOf(patch)
+	if pv.Kind() != reflect.Pointer || pv.IsNil() || pv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("null: Diff patch must be a non-nil pointer to a struct, got %T", patch)
+	}
+	ov, err := diffStruct(old)
+	if err != nil {
+		return err
+	}
+	nv, err := diffStruct(next)
+	if err != nil {
+		return err
+	}
+	if ov.Type() != nv.Type() {
+		return fmt.Errorf("null: Diff cannot compare %s with %s", ov.Type(), nv.Type())
+	}
+
+	c := &diffConfig{equal: make(map[reflect.Type]func(a, b reflect.Value) bool)}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	p, err := planFor(ov.Type(), pv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	pv.Elem().SetZero()
+	_, err = p.diff(c, pv.Elem(), ov, nv)
+	return err
+}
+
+func diffStruct(x any) (reflect.Value, error) {
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return v, fmt.Errorf("null: Diff requires structs, got %T", x)
+	}
+	return v, nil
+}
+
+// diff writes the changes between ov and nv into pv and reports whether
+// anything changed.
+func (p *plan) diff(c *diffConfig, pv, ov, nv reflect.Value) (bool, error) {
+	changed := false
+	for i := range p.fields {
+		f := &p.fields[i]
+
+		o, err := ov.FieldByIndexErr(f.dst)
+		if err != nil {
+			o = reflect.Zero(ov.Type().FieldByIndex(f.dst).Type)
+		}
+		n, err := nv.FieldByIndexErr(f.dst)
+		if err != nil {
+			n = reflect.Zero(nv.Type().FieldByIndex(f.dst).Type)
+		}
+
+		var ch bool
+		switch f.kind {
+		case dstNested, dstNestedPtr:
+			ch, err = f.diffNested(c, pv.Field(f.patch), o, n)
+		default:
+			ch, err = f.diffValue(c, pv.Field(f.patch), o, n)
+		}
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || ch
+	}
+	return changed, nil
+}
+
+func (f *planField) diffValue(c *diffConfig, dst, o, n reflect.Value) (bool, error) {
+	state, x := f.compare(c, o, n)
+	if state == Unset {
+		return false, nil
+	}
+	setter := dst.Addr().Interface().(anySetter)
+	if state == Valid {
+		elem := valueElem(dst.Type())
+		if !x.Type().AssignableTo(elem) {
+			return false, &FieldError{
+				Path: f.name,
+				Err:  fmt.Errorf("%w: cannot assign %s to %s", ErrTypeMismatch, x.Type(), elem),
+			}
+		}
+	}
+	setter.setReflect(state, x)
+	return true, nil
+}
+
+// compare returns the patch state for a domain field that went from o to n,
+// along with the new value when the state is Valid.
+func (f *planField) compare(c *diffConfig, o, n reflect.Value) (State, reflect.Value) {
+	switch f.kind {
+	case dstValue:
+		ov, nv := o.Interface().(anyValue), n.Interface().(anyValue)
+		switch nv.State() {
+		case Null:
+			if ov.State() == Null {
+				return Unset, n
+			}
+			return Null, n
+		case Valid:
+			if ov.State() == Valid && c.eq(ov.reflectValue(), nv.reflectValue()) {
+				return Unset, n
+			}
+			return Valid, nv.reflectValue()
+		}
+		return Unset, n
+	case dstPtr:
+		switch {
+		case n.IsNil() && o.IsNil():
+			return Unset, n
+		case n.IsNil():
+			return Null, n
+		case !o.IsNil() && c.eq(o.Elem(), n.Elem()):
+			return Unset, n
+		}
+		return Valid, n.Elem()
+	default:
+		if isNil(n) {
+			if isNil(o) {
+				return Unset, n
+			}
+			return Null, n
+		}
+		if c.eq(o, n) {
+			return Unset, n
+		}
+		return Valid, n
+	}
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Chan, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+func (f *planField) diffNested(c *diffConfig, dst, o, n reflect.Value) (bool, error) {
+	if f.kind == dstNestedPtr {
+		if o.IsNil() && n.IsNil() {
+			return false, nil
+		}
+		o, n = derefOrZero(o), derefOrZero(n)
+	}
+
+	target := dst
+	if f.ptr {
+		target = reflect.New(dst.Type().Elem()).Elem()
+	}
+	changed, err := f.nested.diff(c, target, o, n)
+	if err != nil {
+		return changed, prefixError(f.name, err)
+	}
+	if changed && f.ptr {
+		dst.Set(target.Addr())
+	}
+	return changed, nil
+}
+
+func derefOrZero(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type().Elem())
+	}
+	return v.Elem()
+}
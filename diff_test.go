@@ -0,0 +1,149 @@
+package null
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DiffSuite struct {
+	suite.Suite
+}
+
+func TestDiffSuite(t *testing.T) {
+	suite.Run(t, new(DiffSuite))
+}
+
+func (s *DiffSuite) TestNoChanges() {
+	u := applyUser{Name: "Alice", Email: New("a@example.com")}
+	var p applyUserPatch
+	s.Require().NoError(Diff(&p, u, u))
+	s.False(p.Name.IsSet())
+	s.False(p.Nickname.IsSet())
+	s.False(p.Email.IsSet())
+	s.False(p.Years.IsSet())
+	s.False(p.Address.Street.IsSet())
+	s.Nil(p.Billing)
+}
+
+func (s *DiffSuite) TestChangedValues() {
+	nick := "ally"
+	old := applyUser{Name: "Alice", Age: 30, Email: New("a@example.com")}
+	next := applyUser{Name: "Bob", Age: 30, Nickname: &nick, Email: New("b@example.com")}
+
+	var p applyUserPatch
+	s.Require().NoError(Diff(&p, &old, &next))
+	s.Equal(New("Bob"), p.Name)
+	s.Equal(New("ally"), p.Nickname)
+	s.Equal(New("b@example.com"), p.Email)
+	s.False(p.Years.IsSet())
+}
+
+func (s *DiffSuite) TestClearedValues() {
+	nick := "ally"
+	old := applyUser{Nickname: &nick, Email: New("a@example.com")}
+	next := applyUser{Email: NewNull[string]()}
+
+	var p applyUserPatch
+	s.Require().NoError(Diff(&p, old, next))
+	s.True(p.Nickname.IsNull())
+	s.True(p.Email.IsNull())
+}
+
+func (s *DiffSuite) TestUnsetValueIsUnchanged() {
+	old := applyUser{Email: New("a@example.com")}
+	var p applyUserPatch
+	s.Require().NoError(Diff(&p, old, applyUser{}))
+	s.False(p.Email.IsSet())
+
+	// Apply cannot make a field Unset, so the old value survives.
+	s.Require().NoError(Apply(&old, p))
+	s.Equal(New("a@example.com"), old.Email)
+}
+
+func (s *DiffSuite) TestResetsPatch() {
+	p := applyUserPatch{Name: New("stale")}
+	s.Require().NoError(Diff(&p, applyUser{}, applyUser{}))
+	s.False(p.Name.IsSet())
+}
+
+func (s *DiffSuite) TestNested() {
+	city := "Paris"
+	old := applyUser{Address: applyAddress{Street: "Main St", City: &city}}
+	next := applyUser{
+		Address: applyAddress{Street: "Main St"},
+		Billing: &applyAddress{Street: "Side St"},
+	}
+
+	var p applyUserPatch
+	s.Require().NoError(Diff(&p, old, next))
+	s.False(p.Address.Street.IsSet())
+	s.True(p.Address.City.IsNull())
+	s.Require().NotNil(p.Billing)
+	s.Equal(New("Side St"), p.Billing.Street)
+	s.False(p.Billing.City.IsSet())
+}
+
+func (s *DiffSuite) TestRoundTripWithApply() {
+	nick := "ally"
+	old := applyUser{Name: "Alice", Nickname: &nick, Age: 30}
+	next := applyUser{Name: "Alice", Age: 31, Email: New("a@example.com"), Billing: &applyAddress{Street: "x"}}
+
+	var p applyUserPatch
+	s.Require().NoError(Diff(&p, old, next))
+	s.Require().NoError(Apply(&old, p))
+	s.Equal(next, old)
+}
+
+func (s *DiffSuite) TestWithEqual() {
+	type event struct{ At time.Time }
+	type eventPatch struct{ At Value[time.Time] }
+
+	utc := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	local := utc.In(time.FixedZone("CET", 3600))
+
+	var p eventPatch
+	s.Require().NoError(Diff(&p, event{At: utc}, event{At: local}))
+	s.True(p.At.IsValid(), "DeepEqual sees different locations")
+
+	s.Require().NoError(Diff(&p, event{At: utc}, event{At: local}, WithEqual(time.Time.Equal)))
+	s.False(p.At.IsSet())
+}
+
+func (s *DiffSuite) TestNilSlice() {
+	type tags struct{ Tags []string }
+	type tagsPatch struct{ Tags Value[[]string] }
+
+	var p tagsPatch
+	s.Require().NoError(Diff(&p, tags{Tags: []string{"a"}}, tags{}))
+	s.True(p.Tags.IsNull())
+
+	s.Require().NoError(Diff(&p, tags{}, tags{Tags: []string{"a"}}))
+	s.Equal([]string{"a"}, p.Tags.Get())
+}
+
+func (s *DiffSuite) TestInvalidArguments() {
+	var p applyUserPatch
+	s.Error(Diff(p, applyUser{}, applyUser{}))
+	s.Error(Diff(&p, "old", applyUser{}))
+	s.Error(Diff(&p, applyUser{}, applyAddress{}))
+}
+
+func (s *DiffSuite) TestInterfaceField() {
+	type dst struct{ N any }
+	type patch struct{ N Value[any] }
+
+	var p patch
+	s.Require().NoError(Diff(&p, dst{}, dst{N: 1}))
+	s.Equal(1, p.N.Get())
+}
+
+func (s *DiffSuite) TestTypeMismatch() {
+	type dst struct{ N any }
+	type patch struct{ N Value[string] }
+
+	var p patch
+	err := Diff(&p, dst{}, dst{N: "x"})
+	s.ErrorIs(err, ErrTypeMismatch)
+}
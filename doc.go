@@ -72,6 +72,40 @@
 //	v := name.GetOr("Bob")   // Returns "Bob" if not valid
 //	p := name.Ptr()          // Returns nil if not valid
 //
+// Transforming values without losing the state:
+//
+//	age := null.Map(dto.Age, strconv.Itoa)       // Unset stays Unset, Null stays Null
+//	name := null.Filter(dto.Name, nonEmpty)      // rejected values become Null
+//	full := null.Zip2(first, last, join)         // Unset if either is Unset, else Null if either is Null
+//	nick := null.Coalesce(override, stored)      // first Valid, else Null if any is Null
+//
+// FlatMap chains functions that return a Value, and Or/OrElse fall back to
+// another Value when the first is not Valid.
+//
+// Comparing values:
+//
+//	null.Equal(a, b)                                   // same state, and equal values if Valid
+//	null.EqualFunc(a, b, time.Time.Equal)              // custom equality
+//	slices.SortFunc(vs, null.Compare[int])             // Unset < Null < Valid
+//	slices.SortFunc(vs, null.Comparer[int](null.NullsLast()))
+//	null.WriteHash(&h, v)                              // maphash, consistent with Equal
+//
+// Printing values: Value[T] implements fmt.Stringer, fmt.GoStringer and
+// fmt.Formatter. Verbs apply to the inner value, while Null and Unset print
+// the NullString and UnsetString constants, or other tokens with Formatted:
+//
+//	fmt.Printf("%.2f", null.New(3.14159))                         // 3.14
+//	fmt.Printf("%v", null.NewNull[int]())                         // <null>
+//	fmt.Printf("%v", null.Formatted(null.NewNull[int](), "-", "")) // -
+//	fmt.Printf("%#v", null.New[int64](42))                        // null.New[int64](42)
+//
+// Logging values: Value[T] implements slog.LogValuer. Valid values log as
+// the inner value, Null as null and Unset not at all. Redact, and
+// RedactAttrs as a handler's ReplaceAttr, hide sensitive values:
+//
+//	slog.Info("req", "name", req.Name, "password", null.Redact(req.Password))
+//	slog.LogAttrs(ctx, slog.LevelInfo, "req", null.Attr("email", req.Email))
+//
 // # JSON Integration
 //
 // Value[T] implements json.Marshaler and json.Unmarshaler with full three-state
@@ -79,7 +113,7 @@
 //
 //	type User struct {
 //	    Name  null.Value[string] `json:"name"`
-//	    Email null.Value[string] `json:"email,omitempty"`
+//	    Email null.Value[string] `json:"email,omitzero"`
 //	}
 //
 //	// Unmarshal distinguishes all three states
@@ -90,7 +124,119 @@
 //	// u.Name.IsNull() == true
 //
 // Note: When marshaling, both unset and null values produce "null" in JSON
-// (JSON has no concept of "unset"). Use omitempty to omit unset fields.
+// (JSON has no concept of "unset"). Value[T] implements IsZero, returning
+// true only for unset values, so tagging a field with omitzero omits exactly
+// the unset fields and the three states survive a marshal/unmarshal round
+// trip:
+//
+//	type Patch struct {
+//	    Name null.Value[string] `json:"name,omitzero"`
+//	}
+//
+// When encoding/json/v2 is available (Go 1.27, or GOEXPERIMENT=jsonv2 on
+// Go 1.25 and 1.26), Value[T] also implements its MarshalJSONTo and
+// UnmarshalJSONFrom methods.
+//
+// # Text Encoding
+//
+// Value[T] implements encoding.TextMarshaler and encoding.TextUnmarshaler,
+// so it works as a JSON map key, with flag.TextVar and with configuration
+// decoders. T's own text methods are used when it has them; otherwise
+// strings, bools, numbers and time.Duration are converted with strconv.
+// The NullText constant ("null") reads and writes as Null; EmptyText[T]
+// wraps a Value to use empty text for Null instead.
+//
+// FlagVar and Flag register a Value as a command-line flag that stays Unset
+// when absent and is Null when passed empty or as NullText; FlagStates
+// reports the state of every flag after parsing:
+//
+//	timeout := null.Flag[time.Duration](fs, "timeout", "request timeout")
+//	fs.Parse(os.Args[1:])   // --timeout=30s, --timeout= or nothing
+//
+// The nullenv subpackage decodes environment variables into structs of
+// Values, where an undefined variable is Unset and an empty one is Null:
+//
+//	err := nullenv.Decode(&cfg, nullenv.WithPrefix("APP_"))
+//
+// The nullform subpackage does the same for url.Values, where an absent key
+// is Unset and an empty key (?status=) is Null:
+//
+//	err := nullform.Decode(r.URL.Query(), &filter)
+//
+// # JSON Merge Patch
+//
+// The mergepatch subpackage implements RFC 7386 (application/merge-patch+json)
+// on top of Value[T]:
+//
+//	import "github.com/bjaus/null/mergepatch"
+//
+//	err := mergepatch.ApplyTo(&user, body)   // null members delete, objects merge
+//	doc, err := mergepatch.Generate(patch)   // Unset fields are omitted
+//
+// # JSON Patch
+//
+// The jsonpatch subpackage converts patch structs to and from RFC 6902
+// operation lists: Null fields become "remove", Valid fields "replace" (or
+// "add") and Unset fields are skipped:
+//
+//	ops, err := jsonpatch.Generate(patch)
+//	err := jsonpatch.Parse(body, &patch)   // rejects move, copy and array paths
+//
+// # XML
+//
+// Value[T] implements xml.Marshaler, xml.Unmarshaler, xml.MarshalerAttr and
+// xml.UnmarshalerAttr. Unset elements and attributes are omitted, Null
+// elements are written and read as xsi:nil="true", Null attributes as
+// NullText:
+//
+//	<user><name>Alice</name><email xmlns:xsi="..." xsi:nil="true"></email></user>
+//
+// # Gob
+//
+// Value[T] implements gob.GobEncoder and gob.GobDecoder. Each Value is
+// encoded as a version byte and a kind byte followed by its binary encoding
+// when T has one, or by a state byte and, when Valid, the gob encoding of T,
+// so Unset and Null survive a round trip. The gob encoding is not compact:
+// every Valid value carries a full type descriptor for T.
+//
+// # Binary Encoding
+//
+// Value[T] implements encoding.BinaryMarshaler, encoding.BinaryUnmarshaler
+// and encoding.BinaryAppender. The state byte is followed, for Valid values,
+// by T's own binary form if it has one, fixed-width big-endian numbers, or a
+// uvarint length and the bytes for strings and []byte:
+//
+//	b, err := null.New(int32(7)).MarshalBinary() // [2 0 0 0 7]
+//
+// # YAML
+//
+// The nullyaml subpackage wraps Value[T] for gopkg.in/yaml.v3. Use
+// nullyaml.Unmarshal rather than yaml.Unmarshal, which skips unmarshalers
+// for null nodes and would leave null fields Unset:
+//
+//	type Config struct {
+//	    Name nullyaml.Value[string] `yaml:"name,omitempty"` // omitted only when Unset
+//	}
+//
+//	err := nullyaml.Unmarshal(data, &cfg)
+//
+// # CBOR
+//
+// The nullcbor subpackage encodes and decodes CBOR using only the standard
+// library. Unset is written as undefined (or omitted from structs), Null as
+// null and Valid as T, so no state is lost:
+//
+//	data, err := nullcbor.Marshal(reading)
+//	err = nullcbor.Unmarshal(data, &reading)
+//
+// # MessagePack
+//
+// The nullmsgpack subpackage encodes and decodes MessagePack using only the
+// standard library. Unset keys are omitted from structs and maps, Null is
+// written as nil and Valid as T:
+//
+//	data, err := nullmsgpack.Marshal(event)
+//	err = nullmsgpack.Unmarshal(data, &event)
 //
 // # SQL Integration
 //
@@ -112,6 +258,17 @@
 // Supported SQL types: string, int/int8/int16/int32/int64, uint/uint8/uint16/uint32/uint64,
 // float32/float64, bool, time.Time, []byte.
 //
+// The nullsql subpackage builds UPDATE and INSERT statements from patch
+// structs, leaving out Unset columns:
+//
+//	type UserPatch struct {
+//	    Name  null.Value[string] `db:"name"`
+//	    Email null.Value[string] `db:"email"`
+//	}
+//
+//	query, args, err := nullsql.Update(nullsql.Dollar, "users", nullsql.Key{"id": id}, patch)
+//	// UPDATE users SET name = $1, email = NULL WHERE id = $2
+//
 // # DynamoDB Integration
 //
 // For DynamoDB support, use the nullddb subpackage which wraps Value[T] with
@@ -133,6 +290,38 @@
 //	apiVal := null.New("Alice")
 //	ddbVal := nullddb.From(apiVal)
 //
+// Strings, numbers, bools, []byte and time.Time are converted directly; any
+// other T (structs, maps, slices, or attributevalue.Marshaler types) goes
+// through the attributevalue encoder with its default options. Options
+// passed to nullddb.MarshalMap and nullddb.UnmarshalMap apply inside
+// Values too.
+//
+// attributevalue.MarshalMap writes both Null and Unset values as NULL.
+// nullddb.MarshalMap omits Unset values instead (keeping sparse indexes
+// sparse), and nullddb.UnmarshalMap decodes into a zeroed struct so absent
+// attributes come back Unset:
+//
+//	av, err := nullddb.MarshalMap(item)
+//	err = nullddb.UnmarshalMap(av, &item)
+//
+// nullddb.BuildUpdate turns a patch struct into an UpdateExpression that
+// skips Unset fields, REMOVEs Null fields (or SETs them to NULL with the
+// setnull tag option) and SETs Valid fields:
+//
+//	u, err := nullddb.BuildUpdate(patch)
+//	// u.UpdateExpression == "SET #a0 = :v0 REMOVE #a1"
+//
+// # Protocol Buffers
+//
+// The nullpb subpackage converts between Value[T] and the Protocol Buffers
+// wrapper types, Timestamp and Duration. A present wrapper is Valid and an
+// absent one Unset; nullpb.Apply then uses the request's FieldMask to make
+// named but absent fields Null and unnamed fields Unset, and nullpb.Mask
+// builds the mask for a patch:
+//
+//	patch := UserPatch{Email: nullpb.From(req.GetUser().GetEmail())}
+//	err := nullpb.Apply(req.GetUpdateMask(), &patch)
+//
 // # State Semantics
 //
 // Value[T] has exactly three states:
@@ -161,6 +350,20 @@
 //	    // If !req.Name.IsSet(), leave user.Name unchanged
 //	}
 //
+// Apply does the same for every field of a patch struct at once, matching
+// fields by name (or by a `null:"Name"` tag) and recursing into nested
+// patch structs:
+//
+//	if err := null.Apply(&user, req); err != nil {
+//	    return err
+//	}
+//
+// Diff goes the other way, producing the minimal patch between two versions
+// of a struct (useful for change events and audit logs):
+//
+//	var change UserPatch
+//	err := null.Diff(&change, before, after, null.WithEqual(time.Time.Equal))
+//
 // Default values:
 //
 //	config := Config{
@@ -182,6 +385,17 @@
 //	    }
 //	}
 //
+// nullsql.Filter builds the same conditions from a filter struct:
+//
+//	type UserFilter struct {
+//	    Status null.Value[string] `db:"status"`
+//	    MinAge null.Value[int]    `db:"age" op:">="`
+//	}
+//
+//	cond, err := nullsql.Filter(filter)
+//	where, args := nullsql.Where(nullsql.Dollar, cond)
+//	// WHERE status IS NULL AND age >= $1
+//
 // # Design Decisions
 //
 // Why not use pointers (*string)?
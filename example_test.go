@@ -3,6 +3,7 @@ package null_test
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/bjaus/null"
 )
@@ -59,3 +60,35 @@ func ExampleValue_GetOr() {
 	fmt.Println(v.GetOr("default"))
 	// Output: default
 }
+
+func ExampleMap() {
+	type UserDTO struct {
+		Age null.Value[string]
+	}
+	type User struct {
+		Age null.Value[int]
+	}
+
+	parse := func(s string) int { n, _ := strconv.Atoi(s); return n }
+
+	for _, dto := range []UserDTO{{}, {Age: null.NewNull[string]()}, {Age: null.New("42")}} {
+		u := User{Age: null.Map(dto.Age, parse)}
+		fmt.Println(u.Age.State(), u.Age.Get())
+	}
+	// Output:
+	// unset 0
+	// null 0
+	// valid 42
+}
+
+func ExampleCoalesce() {
+	override := null.Value[string]{}
+	stored := null.NewNull[string]()
+	fallback := null.New("guest")
+
+	fmt.Println(null.Coalesce(override, stored).State())
+	fmt.Println(null.Coalesce(override, stored, fallback).Get())
+	// Output:
+	// null
+	// guest
+}
@@ -0,0 +1,88 @@
+package null
+
+import (
+	"flag"
+	"reflect"
+)
+
+// FlagVar defines a flag with the given name and usage that stores into p.
+// If fs is nil the flag is defined on flag.CommandLine.
+//
+// p is left untouched, normally Unset, when the flag is not passed. An empty
+// argument or NullText (--timeout= or --timeout=null) sets it to Null and
+// anything else is parsed with UnmarshalText. Flags of a bool kind can be
+// passed without a value (--verbose).
+func FlagVar[T any](fs *flag.FlagSet, p *Value[T], name, usage string) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	fs.Var(&flagValue[T]{p: p}, name, usage)
+}
+
+// Flag is like FlagVar but allocates the Value and returns a pointer to it.
+func Flag[T any](fs *flag.FlagSet, name, usage string) *Value[T] {
+	p := new(Value[T])
+	FlagVar(fs, p, name, usage)
+	return p
+}
+
+// FlagStates reports the state of every flag defined on fs (flag.CommandLine
+// if nil). Flags defined with FlagVar or Flag report the state of their
+// Value; any other flag is Valid if it was passed and Unset if not.
+func FlagStates(fs *flag.FlagSet) map[string]State {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	states := make(map[string]State)
+	fs.VisitAll(func(f *flag.Flag) {
+		states[f.Name] = Unset
+		if v, ok := f.Value.(interface{ State() State }); ok {
+			states[f.Name] = v.State()
+		}
+	})
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := f.Value.(interface{ State() State }); !ok {
+			states[f.Name] = Valid
+		}
+	})
+	return states
+}
+
+// flagValue adapts a *Value[T] to flag.Value and flag.Getter.
+type flagValue[T any] struct {
+	p *Value[T]
+}
+
+// String returns the flag's value as text, or "" when it is Unset. The flag
+// package calls it on a zero flagValue to detect default values, so p may
+// be nil.
+func (f *flagValue[T]) String() string {
+	if f == nil || f.p == nil || f.p.state == Unset {
+		return ""
+	}
+	b, err := f.p.MarshalText()
+	if err != nil {
+		return f.p.String()
+	}
+	return string(b)
+}
+
+func (f *flagValue[T]) Set(s string) error {
+	if s == "" {
+		*f.p = NewNull[T]()
+		return nil
+	}
+	return f.p.UnmarshalText([]byte(s))
+}
+
+func (f *flagValue[T]) Get() any {
+	return *f.p
+}
+
+func (f *flagValue[T]) State() State {
+	return f.p.state
+}
+
+func (f *flagValue[T]) IsBoolFlag() bool {
+	return reflect.TypeFor[T]().Kind() == reflect.Bool
+}
@@ -0,0 +1,104 @@
+package null
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FlagSuite struct {
+	suite.Suite
+	fs *flag.FlagSet
+}
+
+func TestFlagSuite(t *testing.T) {
+	suite.Run(t, new(FlagSuite))
+}
+
+func (s *FlagSuite) SetupTest() {
+	s.fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	s.fs.SetOutput(new(bytes.Buffer))
+}
+
+func (s *FlagSuite) TestThreeStates() {
+	timeout := Flag[time.Duration](s.fs, "timeout", "")
+	name := Flag[string](s.fs, "name", "")
+	retries := Flag[int](s.fs, "retries", "")
+
+	s.Require().NoError(s.fs.Parse([]string{"-timeout", "30s", "-name="}))
+	s.Equal(New(30*time.Second), *timeout)
+	s.True(name.IsNull())
+	s.False(retries.IsSet())
+}
+
+func (s *FlagSuite) TestNullText() {
+	var port Value[int]
+	FlagVar(s.fs, &port, "port", "")
+	s.Require().NoError(s.fs.Parse([]string{"--port=null"}))
+	s.True(port.IsNull())
+}
+
+func (s *FlagSuite) TestBoolFlag() {
+	verbose := Flag[bool](s.fs, "verbose", "")
+	debug := Flag[bool](s.fs, "debug", "")
+	quiet := Flag[bool](s.fs, "quiet", "")
+
+	s.Require().NoError(s.fs.Parse([]string{"-verbose", "-debug=false"}))
+	s.Equal(New(true), *verbose)
+	s.Equal(New(false), *debug)
+	s.False(quiet.IsSet())
+}
+
+func (s *FlagSuite) TestInvalidValue() {
+	Flag[int](s.fs, "retries", "")
+	s.Error(s.fs.Parse([]string{"-retries", "many"}))
+}
+
+func (s *FlagSuite) TestGetter() {
+	Flag[int](s.fs, "retries", "")
+	s.Require().NoError(s.fs.Parse([]string{"-retries", "3"}))
+	g, ok := s.fs.Lookup("retries").Value.(flag.Getter)
+	s.Require().True(ok)
+	s.Equal(New(3), g.Get())
+	s.Equal("3", g.String())
+}
+
+func (s *FlagSuite) TestPrintDefaults() {
+	var buf bytes.Buffer
+	s.fs.SetOutput(&buf)
+	Flag[int](s.fs, "retries", "number of retries")
+	s.NotPanics(s.fs.PrintDefaults)
+	s.Contains(buf.String(), "number of retries")
+	s.NotContains(buf.String(), "default")
+}
+
+func (s *FlagSuite) TestFlagStates() {
+	Flag[int](s.fs, "retries", "")
+	Flag[string](s.fs, "name", "")
+	Flag[string](s.fs, "region", "")
+	s.fs.Bool("dry-run", false, "")
+	s.fs.String("profile", "", "")
+
+	s.Require().NoError(s.fs.Parse([]string{"-retries", "3", "-name", "", "-dry-run"}))
+	s.Equal(map[string]State{
+		"retries": Valid,
+		"name":    Null,
+		"region":  Unset,
+		"dry-run": Valid,
+		"profile": Unset,
+	}, FlagStates(s.fs))
+}
+
+func (s *FlagSuite) TestDefaultFlagSet() {
+	old := flag.CommandLine
+	defer func() { flag.CommandLine = old }()
+	flag.CommandLine = s.fs
+
+	level := Flag[int](nil, "level", "")
+	s.Require().NoError(s.fs.Parse([]string{"-level", "2"}))
+	s.Equal(New(2), *level)
+	s.Equal(Valid, FlagStates(nil)["level"])
+}
@@ -0,0 +1,105 @@
+package null
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NullString and UnsetString are printed in place of the value for Null and
+// Unset Values by String and the fmt verbs. Use Formatted to print other
+// tokens.
+const (
+	NullString  = "<null>"
+	UnsetString = "<unset>"
+)
+
+// String implements fmt.Stringer. Valid values are printed with %v; Null
+// and Unset Values print NullString and UnsetString.
+func (v Value[T]) String() string {
+	switch v.state {
+	case Valid:
+		return fmt.Sprint(v.v)
+	case Null:
+		return NullString
+	default:
+		return UnsetString
+	}
+}
+
+// GoString implements fmt.GoStringer, used by %#v. It prints the Go
+// expression that builds v, e.g. null.New("Alice"), null.New[int64](42),
+// null.NewNull[string]() or null.Value[string]{}.
+func (v Value[T]) GoString() string {
+	t := reflect.TypeFor[T]().String()
+	switch v.state {
+	case Valid:
+		lit := fmt.Sprintf("%#v", v.v)
+		if inferable(reflect.TypeFor[T](), lit) {
+			return "null.New(" + lit + ")"
+		}
+		return "null.New[" + t + "](" + lit + ")"
+	case Null:
+		return "null.NewNull[" + t + "]()"
+	default:
+		return "null.Value[" + t + "]{}"
+	}
+}
+
+// inferable reports whether New(lit) infers type t without an explicit type
+// argument: lit is an untyped string, int or bool constant of that exact
+// type, or a literal that spells out its type.
+func inferable(t reflect.Type, lit string) bool {
+	switch t {
+	case reflect.TypeFor[string](), reflect.TypeFor[int](), reflect.TypeFor[bool]():
+		return true
+	}
+	return t.Kind() != reflect.Interface && strings.HasPrefix(lit, t.String())
+}
+
+// Format implements fmt.Formatter. Valid values are formatted with the same
+// verb and flags, so %d, %.2f, %q and friends apply to the inner value; %#v
+// prints GoString. Null and Unset Values print NullString and UnsetString,
+// honoring only the width and '-' flag.
+func (v Value[T]) Format(f fmt.State, verb rune) {
+	v.format(f, verb, NullString, UnsetString)
+}
+
+// Formatted returns a fmt.Formatter that prints v like Format, but with
+// nullTok and unsetTok in place of NullString and UnsetString:
+//
+//	fmt.Printf("%-6v|\n", null.Formatted(v, "NULL", "-")) // NULL  |
+func Formatted[T any](v Value[T], nullTok, unsetTok string) fmt.Formatter {
+	return formatted[T]{v: v, null: nullTok, unset: unsetTok}
+}
+
+type formatted[T any] struct {
+	v           Value[T]
+	null, unset string
+}
+
+func (x formatted[T]) Format(f fmt.State, verb rune) {
+	x.v.format(f, verb, x.null, x.unset)
+}
+
+func (v Value[T]) format(f fmt.State, verb rune, nullTok, unsetTok string) {
+	if verb == 'v' && f.Flag('#') {
+		_, _ = f.Write([]byte(v.GoString()))
+		return
+	}
+	if v.state == Valid {
+		_, _ = fmt.Fprintf(f, fmt.FormatString(f, verb), v.v)
+		return
+	}
+
+	format := "%*s"
+	if f.Flag('-') {
+		format = "%-*s"
+	}
+	tok := unsetTok
+	if v.state == Null {
+		tok = nullTok
+	}
+	w, _ := f.Width()
+	_, _ = fmt.Fprintf(f, format, w, tok)
+}
@@ -0,0 +1,121 @@
+package null
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type formatColor string
+
+type formatPoint struct{ X, Y int }
+
+func TestFormat(t *testing.T) {
+	tests := map[string]struct {
+		format string
+		v      any
+		want   string
+	}{
+		"v valid":     {"%v", New("Alice"), "Alice"},
+		"v null":      {"%v", NewNull[string](), "<null>"},
+		"v unset":     {"%v", Value[string]{}, "<unset>"},
+		"s valid":     {"%s", New("Alice"), "Alice"},
+		"d valid":     {"%d", New(42), "42"},
+		"padded int":  {"%05d", New(42), "00042"},
+		"float":       {"%.2f", New(3.14159), "3.14"},
+		"float null":  {"%.2f", NewNull[float64](), "<null>"},
+		"quoted":      {"%q", New("hi"), `"hi"`},
+		"quoted null": {"%q", NewNull[string](), "<null>"},
+		"hex":         {"%x", New(255), "ff"},
+		"width null":  {"%8v", NewNull[int](), "  <null>"},
+		"left null":   {"%-8v|", NewNull[int](), "<null>  |"},
+		"narrow null": {"%2v", NewNull[int](), "<null>"},
+		"plus struct": {"%+v", New(formatPoint{1, 2}), "{X:1 Y:2}"},
+		"nested":      {"%v", struct{ A Value[int] }{NewNull[int]()}, "{<null>}"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fmt.Sprintf(tt.format, tt.v))
+		})
+	}
+}
+
+func TestFormat_Pointer(t *testing.T) {
+	v := New(7)
+	assert.Equal(t, "7", fmt.Sprintf("%v", &v))
+}
+
+func TestGoString(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		v    fmt.GoStringer
+		want string
+	}{
+		"string":      {New("Alice"), `null.New("Alice")`},
+		"int":         {New(42), `null.New(42)`},
+		"bool":        {New(true), `null.New(true)`},
+		"int64":       {New[int64](42), `null.New[int64](42)`},
+		"float64":     {New(1.0), `null.New[float64](1)`},
+		"named":       {New(formatColor("red")), `null.New[null.formatColor]("red")`},
+		"struct":      {New(formatPoint{1, 2}), `null.New(null.formatPoint{X:1, Y:2})`},
+		"slice":       {New([]string{"a"}), `null.New([]string{"a"})`},
+		"time":        {New(day), `null.New[time.Time](time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC))`},
+		"interface":   {New[any](1), `null.New[interface {}](1)`},
+		"null":        {NewNull[string](), `null.NewNull[string]()`},
+		"null struct": {NewNull[formatPoint](), `null.NewNull[null.formatPoint]()`},
+		"unset":       {Value[string]{}, `null.Value[string]{}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.v.GoString())
+			assert.Equal(t, tt.want, fmt.Sprintf("%#v", tt.v))
+		})
+	}
+}
+
+type StringSuite struct {
+	suite.Suite
+}
+
+func TestStringSuite(t *testing.T) {
+	suite.Run(t, new(StringSuite))
+}
+
+func (s *StringSuite) TestString() {
+	s.Equal("Alice", New("Alice").String())
+	s.Equal("42", New(42).String())
+	s.Equal("<null>", NewNull[int]().String())
+	s.Equal("<unset>", Value[int]{}.String())
+}
+
+func (s *StringSuite) TestTokensIgnoreVerb() {
+	s.Equal(NullString, fmt.Sprintf("%d", NewNull[int]()))
+	s.Equal(UnsetString, fmt.Sprintf("%.2f", Value[float64]{}))
+	s.Equal(UnsetString, fmt.Sprint(Value[int]{}))
+}
+
+func (s *StringSuite) TestFormatted() {
+	tests := map[string]struct {
+		format string
+		v      fmt.Formatter
+		want   string
+	}{
+		"null":      {"%v", Formatted(NewNull[int](), "NULL", "-"), "NULL"},
+		"unset":     {"%v", Formatted(Value[int]{}, "NULL", "-"), "-"},
+		"valid":     {"%05d", Formatted(New(42), "NULL", "-"), "00042"},
+		"width":     {"%-6v|", Formatted(NewNull[int](), "NULL", "-"), "NULL  |"},
+		"empty":     {"[%v]", Formatted(NewNull[string](), "", ""), "[]"},
+		"go syntax": {"%#v", Formatted(NewNull[string](), "NULL", "-"), "null.NewNull[string]()"},
+	}
+	for name, tt := range tests {
+		s.Run(name, func() {
+			s.Equal(tt.want, fmt.Sprintf(tt.format, tt.v))
+		})
+	}
+
+	// The package tokens are unchanged.
+	s.Equal(NullString, fmt.Sprint(NewNull[int]()))
+}
@@ -0,0 +1,121 @@
+package null
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// gobVersion is the first byte of every gob encoding so the format can
+// change without breaking values that are already stored. It is followed
+// by one of the gob kinds, naming how the rest is encoded.
+const gobVersion byte = 1
+
+// The gob kinds. gobKindBinary is followed by the binary encoding of the
+// Value; gobKindGob by a state byte and, for Valid values, the gob encoding
+// of the value.
+const (
+	gobKindBinary byte = 1
+	gobKindGob    byte = 2
+)
+
+// GobEncode implements gob.GobEncoder.
+//
+// The encoding is a version byte and a kind byte. Bools, numbers, strings,
+// []byte and types that implement encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler but not gob.GobEncoder use the binary kind: the
+// MarshalBinary encoding follows. Anything else uses the gob kind: a state
+// byte follows and, for Valid values, the gob encoding of the value. That is
+// not compact, since each value is encoded by its own gob.Encoder and so
+// carries a full description of T; a Valid struct costs tens of bytes on
+// top of its data. T must be encodable by gob, and as with gob itself,
+// empty slices and maps in the gob kind decode as nil; a nil []byte in the
+// binary kind decodes as empty.
+func (v Value[T]) GobEncode() ([]byte, error) {
+	if gobBinary(reflect.TypeFor[T]()) {
+		return v.AppendBinary([]byte{gobVersion, gobKindBinary})
+	}
+	buf := bytes.NewBuffer([]byte{gobVersion, gobKindGob, byte(v.state)})
+	if v.state != Valid {
+		return buf.Bytes(), nil
+	}
+	if err := gob.NewEncoder(buf).Encode(&v.v); err != nil {
+		return nil, fmt.Errorf("null: gob: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder. It accepts the output of GobEncode,
+// following the kind byte rather than T, so it reads either kind.
+func (v *Value[T]) GobDecode(data []byte) error {
+	if len(data) < 3 {
+		return errors.New("null: gob: data too short")
+	}
+	if data[0] != gobVersion {
+		return fmt.Errorf("null: gob: unsupported version %d", data[0])
+	}
+	switch data[1] {
+	case gobKindBinary:
+		return v.UnmarshalBinary(data[2:])
+	case gobKindGob:
+	default:
+		return fmt.Errorf("null: gob: unsupported kind %d", data[1])
+	}
+
+	switch state := State(data[2]); state {
+	case Unset, Null:
+		if len(data) != 3 {
+			return fmt.Errorf("null: gob: unexpected payload for %s value", state)
+		}
+		*v = Value[T]{state: state}
+		return nil
+	case Valid:
+		var x T
+		r := bytes.NewReader(data[3:])
+		if err := gob.NewDecoder(r).Decode(&x); err != nil {
+			return fmt.Errorf("null: gob: %w", err)
+		}
+		if r.Len() != 0 {
+			return errors.New("null: gob: trailing data")
+		}
+		*v = New(x)
+		return nil
+	default:
+		return fmt.Errorf("null: gob: invalid state %d", data[2])
+	}
+}
+
+var (
+	gobEncoderType        = reflect.TypeFor[gob.GobEncoder]()
+	binaryAppenderType    = reflect.TypeFor[encoding.BinaryAppender]()
+	binaryMarshalerType   = reflect.TypeFor[encoding.BinaryMarshaler]()
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+// gobBinary reports whether GobEncode writes values of type t in the binary
+// kind: t has no GobEncode method, and either has matching binary
+// methods or has none and is a bool, number, string or byte slice.
+func gobBinary(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	switch {
+	case pt.Implements(gobEncoderType):
+		return false
+	case t.Implements(binaryAppenderType) || t.Implements(binaryMarshalerType):
+		return pt.Implements(binaryUnmarshalerType)
+	case pt.Implements(binaryAppenderType) || pt.Implements(binaryMarshalerType) || pt.Implements(binaryUnmarshalerType):
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
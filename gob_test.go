@@ -0,0 +1,248 @@
+package null
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type gobRecord struct {
+	Name  Value[string]
+	Email Value[string]
+	Age   Value[int]
+	Tags  Value[[]string]
+	Since Value[time.Time]
+}
+
+func gobRoundTrip[T any](t *testing.T, in T) T {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(in))
+	var out T
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&out))
+	return out
+}
+
+func TestGobEncode(t *testing.T) {
+	tests := map[string]struct {
+		v    gob.GobEncoder
+		want []byte
+	}{
+		"unset":     {Value[string]{}, []byte{gobVersion, gobKindBinary, byte(Unset)}},
+		"null":      {NewNull[string](), []byte{gobVersion, gobKindBinary, byte(Null)}},
+		"valid":     {New("hi"), []byte{gobVersion, gobKindBinary, byte(Valid), 2, 'h', 'i'}},
+		"gob unset": {Value[[]string]{}, []byte{gobVersion, gobKindGob, byte(Unset)}},
+		"gob null":  {NewNull[[]string](), []byte{gobVersion, gobKindGob, byte(Null)}},
+		"gob valid": {New([]string{"hi"}), gobKind(t, []string{"hi"})},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := tt.v.GobEncode()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b)
+		})
+	}
+}
+
+func TestGobDecode_Errors(t *testing.T) {
+	valid := gobKind(t, "x")
+
+	tests := map[string]struct {
+		data []byte
+		want string
+	}{
+		"empty":          {nil, "too short"},
+		"no state":       {[]byte{gobVersion, gobKindGob}, "too short"},
+		"bad version":    {[]byte{9, gobKindGob, byte(Null)}, "unsupported version 9"},
+		"bad kind":       {[]byte{gobVersion, 9, byte(Null)}, "unsupported kind 9"},
+		"bad state":      {[]byte{gobVersion, gobKindGob, 7}, "invalid state 7"},
+		"null payload":   {[]byte{gobVersion, gobKindGob, byte(Null), 0}, "unexpected payload"},
+		"valid no data":  {[]byte{gobVersion, gobKindGob, byte(Valid)}, "null: gob:"},
+		"trailing data":  {append(valid, 0), "trailing data"},
+		"wrong type":     {gobKind(t, 1.5), "null: gob:"},
+		"truncated data": {valid[:len(valid)-1], "null: gob:"},
+		"binary state":   {[]byte{gobVersion, gobKindBinary, 7}, "null: binary: invalid state 7"},
+		"binary length":  {append(mustGob(t, New("x")), 0), "null: binary: invalid length prefix"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var v Value[string]
+			assert.ErrorContains(t, v.GobDecode(tt.data), tt.want)
+		})
+	}
+}
+
+// gobKind returns the gob kind encoding of a Valid x, which GobEncode writes
+// only for types without a binary encoding.
+func gobKind[T any](t *testing.T, x T) []byte {
+	t.Helper()
+	buf := bytes.NewBuffer([]byte{gobVersion, gobKindGob, byte(Valid)})
+	require.NoError(t, gob.NewEncoder(buf).Encode(&x))
+	return buf.Bytes()
+}
+
+func mustGob[T any](t *testing.T, v Value[T]) []byte {
+	t.Helper()
+	b, err := v.GobEncode()
+	require.NoError(t, err)
+	return b
+}
+
+func TestGobSize(t *testing.T) {
+	tests := map[string]struct {
+		data []byte
+		want int
+	}{
+		"int":      {mustGob(t, New(42)), 11},
+		"string":   {mustGob(t, New("Alice")), 9},
+		"bytes":    {mustGob(t, New([]byte{1, 2})), 6},
+		"duration": {mustGob(t, New(time.Second)), 11},
+		"null":     {mustGob(t, NewNull[gobRecord]()), 3},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Len(t, tt.data, tt.want)
+		})
+	}
+
+	// Other types carry gob's type description in every Valid value.
+	assert.Greater(t, len(mustGob(t, New(gobRecord{}))), 50)
+}
+
+func TestGobDecode_EitherKind(t *testing.T) {
+	var s Value[string]
+	require.NoError(t, s.GobDecode(gobKind(t, "Alice")))
+	assert.Equal(t, New("Alice"), s)
+
+	var n Value[int]
+	require.NoError(t, n.GobDecode(gobKind(t, 42)))
+	assert.Equal(t, New(42), n)
+
+	var r Value[int]
+	require.NoError(t, r.GobDecode([]byte{gobVersion, gobKindGob, byte(Null)}))
+	assert.Equal(t, NewNull[int](), r)
+}
+
+type GobSuite struct {
+	suite.Suite
+}
+
+func TestGobSuite(t *testing.T) {
+	suite.Run(t, new(GobSuite))
+}
+
+func (s *GobSuite) TestStates() {
+	for _, v := range []Value[string]{{}, NewNull[string](), New(""), New("Alice")} {
+		s.Equal(v, gobRoundTrip(s.T(), v), v.GoString())
+	}
+}
+
+func (s *GobSuite) TestStruct() {
+	in := gobRecord{
+		Name:  New("Alice"),
+		Email: NewNull[string](),
+		Tags:  New([]string{"a", "b"}),
+		Since: New(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)),
+	}
+	out := gobRoundTrip(s.T(), in)
+	s.Equal(in.Name, out.Name)
+	s.Equal(in.Email, out.Email)
+	s.False(out.Age.IsSet())
+	s.Equal(in.Tags, out.Tags)
+	s.True(in.Since.Get().Equal(out.Since.Get()))
+}
+
+func (s *GobSuite) TestNestedValues() {
+	in := New(gobRecord{Name: NewNull[string](), Age: New(0)})
+	s.Equal(in, gobRoundTrip(s.T(), in))
+}
+
+func (s *GobSuite) TestSliceAndMap() {
+	in := map[string][]Value[int]{"a": {New(1), NewNull[int](), {}}}
+	s.Equal(in, gobRoundTrip(s.T(), in))
+}
+
+func (s *GobSuite) TestUnsupportedType() {
+	_, err := New(func() {}).GobEncode()
+	s.ErrorContains(err, "null: gob:")
+}
+
+// --- Fuzzing ---
+
+func fuzzState(b byte) State {
+	return State(b % 3)
+}
+
+func fuzzValue[T any](state State, x T) Value[T] {
+	switch state {
+	case Valid:
+		return New(x)
+	case Null:
+		return NewNull[T]()
+	default:
+		return Value[T]{}
+	}
+}
+
+func checkGob[T any](t *testing.T, in Value[T]) {
+	t.Helper()
+	b, err := in.GobEncode()
+	require.NoError(t, err)
+	var out Value[T]
+	require.NoError(t, out.GobDecode(b))
+	assert.Equal(t, in, out)
+}
+
+func FuzzGob(f *testing.F) {
+	f.Add(byte(Unset), "", int64(0), 0.0, false, []byte(nil), int64(0))
+	f.Add(byte(Null), "x", int64(-1), 1.5, true, []byte{}, int64(1))
+	f.Add(byte(Valid), "héllo", int64(1<<62), -0.25, true, []byte{0, 1, 2}, int64(1705312800000000000))
+	f.Fuzz(func(t *testing.T, sb byte, s string, i int64, fl float64, b bool, bs []byte, ns int64) {
+		state := fuzzState(sb)
+		checkGob(t, fuzzValue(state, s))
+		checkGob(t, fuzzValue(state, i))
+		checkGob(t, fuzzValue(state, uint8(i)))
+		checkGob(t, fuzzValue(state, b))
+		if bs == nil {
+			bs = []byte{} // the binary encoding does not distinguish nil and empty slices
+		}
+		checkGob(t, fuzzValue(state, bs))
+		checkGob(t, fuzzValue(state, time.Duration(ns)))
+		checkGob(t, fuzzValue(state, gobRecord{Name: fuzzValue(state, s), Age: fuzzValue(state, int(i))}))
+		if !math.IsNaN(fl) {
+			checkGob(t, fuzzValue(state, fl))
+		}
+
+		ts := time.Unix(0, ns).UTC()
+		in := fuzzValue(state, ts)
+		data, err := in.GobEncode()
+		require.NoError(t, err)
+		var out Value[time.Time]
+		require.NoError(t, out.GobDecode(data))
+		assert.Equal(t, in.State(), out.State())
+		assert.True(t, in.Get().Equal(out.Get()))
+	})
+}
+
+func FuzzGobDecode(f *testing.F) {
+	f.Add([]byte{gobVersion, gobKindGob, byte(Null)})
+	f.Add([]byte{gobVersion, gobKindGob, byte(Valid), 3, 4, 0, 2})
+	f.Add([]byte{gobVersion, gobKindBinary, byte(Valid), 2, 'h', 'i'})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v Value[string]
+		if v.GobDecode(data) != nil {
+			return
+		}
+		b, err := v.GobEncode()
+		require.NoError(t, err)
+		var again Value[string]
+		require.NoError(t, again.GobDecode(b))
+		assert.Equal(t, v, again)
+	})
+}
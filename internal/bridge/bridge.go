@@ -0,0 +1,18 @@
+// Package bridge lets the subpackages build null.Values through reflection
+// without widening the public API of package null, which installs the
+// functions below when it is initialized.
+package bridge
+
+import "reflect"
+
+var (
+	// IsValue reports whether t is a null.Value[T] or embeds one.
+	IsValue func(t reflect.Type) bool
+
+	// Elem returns T for a type t for which IsValue reports true.
+	Elem func(t reflect.Type) reflect.Type
+
+	// Set stores x as Valid in the addressable Value v, or makes v Null if x
+	// is the zero reflect.Value. x must be assignable to Elem(v.Type()).
+	Set func(v, x reflect.Value)
+)
@@ -0,0 +1,150 @@
+// Package codec holds the reflection walk shared by the binary codecs,
+// nullcbor and nullmsgpack, which name struct fields by their own struct
+// tag, falling back to the json tag.
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/jsonfield"
+)
+
+// MaxDepth bounds the nesting of encoded and decoded values so that
+// malicious input or cyclic values cannot exhaust the stack.
+const MaxDepth = 1000
+
+// ErrDepth is returned when a value is nested deeper than MaxDepth.
+var ErrDepth = errors.New("exceeded max nesting depth")
+
+// Depth counts the nesting of the value being encoded or decoded.
+type Depth int
+
+// Enter descends one level, failing with ErrDepth past MaxDepth. Every
+// call must be paired with Leave.
+func (d *Depth) Enter() error {
+	if *d++; *d > MaxDepth {
+		return ErrDepth
+	}
+	return nil
+}
+
+// Leave ascends one level.
+func (d *Depth) Leave() { *d-- }
+
+// --- Encoding ---
+
+// Value returns the state of the null.Value v and, when it is Valid, the
+// value it holds.
+func Value(v reflect.Value) (null.State, reflect.Value) {
+	state := v.Interface().(interface{ State() null.State }).State()
+	if state != null.Valid {
+		return state, reflect.Value{}
+	}
+	return state, v.MethodByName("Get").Call(nil)[0]
+}
+
+// StructFields yields the name and value of each field of struct v to
+// encode, in field order. Fields behind nil embedded pointers, Unset
+// Values and empty omitempty fields are left out.
+func StructFields(v reflect.Value, tagKey string) iter.Seq2[string, reflect.Value] {
+	return func(yield func(string, reflect.Value) bool) {
+		for _, f := range Fields(v.Type(), tagKey) {
+			fv, ok := ByIndex(v, f.Index)
+			if !ok || IsUnset(fv) || (f.OmitEmpty && jsonfield.IsEmpty(fv)) {
+				continue
+			}
+			if !yield(f.Name, fv) {
+				return
+			}
+		}
+	}
+}
+
+// IsUnset reports whether v is an Unset null.Value.
+func IsUnset(v reflect.Value) bool {
+	return bridge.IsValue(v.Type()) && !v.Interface().(interface{ IsSet() bool }).IsSet()
+}
+
+// --- Decoding ---
+
+// Fits reports whether n items of at least one byte each can fit in rest,
+// so that a length can be rejected before anything is allocated for it.
+func Fits(n uint64, rest []byte) bool {
+	return n <= uint64(len(rest))
+}
+
+// DecodeValue decodes into the null.Value v by decoding a T with decode
+// and storing it as Valid. The Null and Unset encodings are left to the
+// caller, as they differ between formats.
+func DecodeValue(v reflect.Value, decode func(x reflect.Value) error) error {
+	x := reflect.New(bridge.Elem(v.Type())).Elem()
+	if err := decode(x); err != nil {
+		return err
+	}
+	bridge.Set(v, x)
+	return nil
+}
+
+// StructField returns the field of struct v to decode the value of key
+// into, allocating nil embedded pointers on the way. It reports false if
+// no field matches key.
+func StructField(v reflect.Value, fields []Field, key string) (reflect.Value, bool, error) {
+	f, ok := Lookup(fields, key)
+	if !ok {
+		return reflect.Value{}, false, nil
+	}
+	fv, err := ByIndexAlloc(v, f.Index)
+	return fv, true, err
+}
+
+// SetMapIndex stores e under k in the map v, rejecting keys that cannot
+// be hashed, such as a slice held by an interface key.
+func SetMapIndex(v, k, e reflect.Value) error {
+	if !k.Comparable() {
+		return fmt.Errorf("unhashable map key of type %s", dynamicType(k))
+	}
+	v.SetMapIndex(k, e)
+	return nil
+}
+
+// AnyMap builds the map for decoding into an empty interface from its
+// decoded keys and values: a map[string]any when every key is a string,
+// and a map[any]any otherwise.
+func AnyMap(keys, values []any) (any, error) {
+	strKeys := true
+	for _, k := range keys {
+		if _, ok := k.(string); !ok {
+			strKeys = false
+			break
+		}
+	}
+	if strKeys {
+		m := make(map[string]any, len(keys))
+		for i, k := range keys {
+			m[k.(string)] = values[i]
+		}
+		return m, nil
+	}
+	m := make(map[any]any, len(keys))
+	for i, k := range keys {
+		if !reflect.ValueOf(k).Comparable() {
+			return nil, fmt.Errorf("unhashable map key of type %T", k)
+		}
+		m[k] = values[i]
+	}
+	return m, nil
+}
+
+// dynamicType returns the type of the value held by v if v is an interface,
+// or the type of v otherwise.
+func dynamicType(v reflect.Value) reflect.Type {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return v.Elem().Type()
+	}
+	return v.Type()
+}
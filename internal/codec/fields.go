@@ -0,0 +1,148 @@
+package codec
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bjaus/null/internal/bridge"
+)
+
+// Field is an encoded field of a struct.
+type Field struct {
+	Name      string
+	Index     []int
+	OmitEmpty bool
+}
+
+type cacheKey struct {
+	t   reflect.Type
+	key string
+}
+
+var (
+	timeType = reflect.TypeFor[time.Time]()
+	cache    sync.Map // cacheKey -> []Field
+)
+
+// Fields lists the encoded fields of struct type t, named by the tagKey
+// tag or, if a field has none, by its json tag. As in encoding/json, the
+// fields of untagged embedded structs and struct pointers other than
+// time.Time are flattened, even when the embedded type is unexported,
+// unless t has a field of the same name.
+func Fields(t reflect.Type, tagKey string) []Field {
+	ck := cacheKey{t, tagKey}
+	if fs, ok := cache.Load(ck); ok {
+		return fs.([]Field)
+	}
+	fields := collect(t, tagKey, []reflect.Type{t})
+	cache.Store(ck, fields)
+	return fields
+}
+
+// collect lists the fields of t. Embedded types already in stack are
+// skipped, so that a struct embedding a pointer to itself terminates.
+func collect(t reflect.Type, tagKey string, stack []reflect.Type) []Field {
+	var fields, embedded []Field
+	seen := make(map[string]bool)
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(tagKey)
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType && !bridge.IsValue(ft) {
+				if slices.Contains(stack, ft) {
+					continue
+				}
+				for _, f := range collect(ft, tagKey, append(stack, ft)) {
+					f.Index = append([]int{i}, f.Index...)
+					embedded = append(embedded, f)
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, Field{
+			Name:      name,
+			Index:     []int{i},
+			OmitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+		seen[name] = true
+	}
+	for _, f := range embedded {
+		if !seen[f.Name] {
+			fields = append(fields, f)
+			seen[f.Name] = true
+		}
+	}
+	return fields
+}
+
+// ByIndex is like reflect.Value.FieldByIndex but reports false when the
+// field is behind a nil embedded struct pointer, which encodes nothing.
+func ByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// ByIndexAlloc is like reflect.Value.FieldByIndex but allocates nil
+// embedded struct pointers along the way, which is not possible for
+// pointers to unexported types.
+func ByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// Lookup finds the field for a map key, preferring an exact match over a
+// case-insensitive one.
+func Lookup(fields []Field, key string) (Field, bool) {
+	for _, f := range fields {
+		if f.Name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, key) {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
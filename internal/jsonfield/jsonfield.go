@@ -0,0 +1,227 @@
+// Package jsonfield maps JSON member names to struct fields the way
+// encoding/json does, for the packages that patch structs field by field.
+package jsonfield
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/bjaus/null/internal/bridge"
+)
+
+var (
+	marshalerType   = reflect.TypeFor[json.Marshaler]()
+	unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+)
+
+// IsStruct reports whether t is a struct that encoding/json would encode
+// field by field: not a Value, and without its own JSON methods.
+func IsStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || bridge.IsValue(t) {
+		return false
+	}
+	p := reflect.PointerTo(t)
+	return !t.Implements(marshalerType) && !p.Implements(marshalerType) && !p.Implements(unmarshalerType)
+}
+
+// Field is a JSON member of a struct.
+type Field struct {
+	Name      string
+	Index     []int
+	OmitEmpty bool
+	OmitZero  bool
+
+	tagged bool
+}
+
+// Omit reports whether encoding/json would leave out the member for v,
+// the value of field f, under its omitempty and omitzero options.
+func (f Field) Omit(v reflect.Value) bool {
+	return f.OmitEmpty && IsEmpty(v) || f.OmitZero && isZero(v)
+}
+
+// Fields lists the JSON members of struct type t in field order, following
+// the rules of encoding/json: untagged embedded structs are flattened, and
+// of several fields with the same name the shallowest one wins, a tagged
+// one breaking a tie. Names that remain tied are dropped.
+func Fields(t reflect.Type) []Field {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var fields []Field
+	next := []embedded{{typ: t}}
+	visited := make(map[reflect.Type]bool)
+	count := make(map[reflect.Type]int)
+
+	for len(next) > 0 {
+		current := next
+		next = nil
+		nextCount := make(map[reflect.Type]int)
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := range e.typ.NumField() {
+				sf := e.typ.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(append([]int(nil), e.index...), i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					// Embedded twice at one depth, its fields tie.
+					nextCount[ft]++
+					if nextCount[ft] == 1 {
+						next = append(next, embedded{typ: ft, index: index})
+					}
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+				f := Field{
+					Name:      name,
+					Index:     index,
+					OmitEmpty: hasOption(opts, "omitempty"),
+					OmitZero:  hasOption(opts, "omitzero"),
+					tagged:    name != "",
+				}
+				if f.Name == "" {
+					f.Name = sf.Name
+				}
+				fields = append(fields, f)
+				if count[e.typ] > 1 {
+					fields = append(fields, f)
+				}
+			}
+		}
+		count = nextCount
+	}
+
+	// Sort by name, then by dominance, and keep the dominant field of each
+	// name.
+	slices.SortStableFunc(fields, func(a, b Field) int {
+		if c := cmp.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(a.Index), len(b.Index)); c != 0 {
+			return c
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.Index, b.Index)
+	})
+	out := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].Name == fields[i].Name {
+			j++
+		}
+		if j-i == 1 || len(fields[i].Index) != len(fields[i+1].Index) || fields[i].tagged != fields[i+1].tagged {
+			out = append(out, fields[i])
+		}
+		i = j
+	}
+	slices.SortFunc(out, func(a, b Field) int { return slices.Compare(a.Index, b.Index) })
+	return out
+}
+
+// Lookup returns the field named name.
+func Lookup(fields []Field, name string) (Field, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// LookupFold is like Lookup but falls back to a case-insensitive match, as
+// json.Unmarshal does.
+func LookupFold(fields []Field, name string) (Field, bool) {
+	if f, ok := Lookup(fields, name); ok {
+		return f, true
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// ByIndexAlloc is like reflect.Value.FieldByIndex but allocates nil
+// embedded struct pointers along the way.
+func ByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+func hasOption(opts, opt string) bool {
+	for o := range strings.SplitSeq(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty reports whether v is empty in the sense of encoding/json's
+// omitempty.
+func IsEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// isZero reports whether v is zero in the sense of encoding/json's
+// omitzero: by its IsZero method if it has one, and reflect.Value.IsZero
+// otherwise.
+func isZero(v reflect.Value) bool {
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		return z.IsZero()
+	}
+	if v.CanAddr() {
+		if z, ok := v.Addr().Interface().(interface{ IsZero() bool }); ok {
+			return z.IsZero()
+		}
+	}
+	return v.IsZero()
+}
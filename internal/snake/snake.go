@@ -0,0 +1,38 @@
+// Package snake converts Go field names to snake case, for the subpackages
+// that derive names from fields: protobuf field paths in nullpb and
+// environment variables in nullenv.
+package snake
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Lower converts a Go field name to lower snake case, keeping initialisms
+// together: UserID becomes user_id, HTTPProxy http_proxy and HTTP2Off
+// http2_off.
+func Lower(name string) string {
+	return convert(name, unicode.ToLower)
+}
+
+// Upper converts a Go field name to upper snake case: MaxConns becomes
+// MAX_CONNS and DBHost becomes DB_HOST.
+func Upper(name string) string {
+	return convert(name, unicode.ToUpper)
+}
+
+func convert(name string, to func(rune) rune) string {
+	rs := []rune(name)
+	var b strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(to(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+// Package textconv converts single values to and from text. It backs
+// null.Value's MarshalText and UnmarshalText and the text-based decoders in
+// the subpackages, so they all accept the same input.
+package textconv
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Marshal encodes v with its own MarshalText if it has one, as
+// time.Duration.String for durations, and with strconv for strings, bools
+// and numbers (including named types of those kinds). []byte is returned
+// as is.
+func Marshal(v reflect.Value) ([]byte, error) {
+	switch x := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		return x.MarshalText()
+	case time.Duration:
+		return []byte(x.String()), nil
+	case []byte:
+		return x, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String()), nil
+	case reflect.Bool:
+		return strconv.AppendBool(nil, v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(nil, v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(nil, v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.AppendFloat(nil, v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return nil, fmt.Errorf("null: cannot marshal %s as text", v.Type())
+}
+
+// Unmarshal decodes text into the settable v, the inverse of Marshal.
+func Unmarshal(v reflect.Value, text []byte) error {
+	switch p := v.Addr().Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return p.UnmarshalText(text)
+	case *time.Duration:
+		d, err := time.ParseDuration(string(text))
+		if err != nil {
+			return fmt.Errorf("null: cannot parse duration %q: %w", text, err)
+		}
+		*p = d
+		return nil
+	case *[]byte:
+		*p = append([]byte(nil), text...)
+		return nil
+	}
+
+	s := string(text)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return parseError(s, v.Type(), err)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return parseError(s, v.Type(), err)
+		}
+		v.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return parseError(s, v.Type(), err)
+		}
+		v.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return parseError(s, v.Type(), err)
+		}
+		v.SetFloat(f)
+		return nil
+	}
+	return fmt.Errorf("null: cannot unmarshal text into %s", v.Type())
+}
+
+// IsText reports whether values of type t are converted as a single text
+// value by Marshal and Unmarshal.
+func IsText(t reflect.Type) bool {
+	if t.Implements(textUnmarshaler) || reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return t == bytesType
+}
+
+var (
+	textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+	bytesType       = reflect.TypeFor[[]byte]()
+)
+
+func parseError(s string, t reflect.Type, err error) error {
+	return fmt.Errorf("null: cannot parse %q as %s: %w", s, t, err)
+}
@@ -0,0 +1,43 @@
+//go:build goexperiment.jsonv2 && !go1.27
+
+// This file mirrors json_v2.go for Go 1.25 and 1.26 toolchains, where
+// encoding/json/v2 is only available behind GOEXPERIMENT=jsonv2. From Go 1.27
+// the API is versioned, so json_v2.go carries a go1.27 constraint to raise
+// its language version; keep the two files in sync. json_v2_test.go runs
+// against both.
+
+package null
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+)
+
+// MarshalJSONTo implements the MarshalerTo interface of encoding/json/v2.
+// Valid values are encoded with the encoder's options; null and unset values
+// are encoded as null.
+func (v Value[T]) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if v.state == Valid {
+		return jsonv2.MarshalEncode(enc, v.v)
+	}
+	return enc.WriteToken(jsontext.Null)
+}
+
+// UnmarshalJSONFrom implements the UnmarshalerFrom interface of
+// encoding/json/v2. A JSON null makes the Value Null; anything else makes it
+// Valid. Absent members are never decoded, so they stay Unset.
+func (v *Value[T]) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	if dec.PeekKind() == 'n' {
+		if _, err := dec.ReadToken(); err != nil {
+			return err
+		}
+		*v = NewNull[T]()
+		return nil
+	}
+	var val T
+	if err := jsonv2.UnmarshalDecode(dec, &val); err != nil {
+		return err
+	}
+	*v = New(val)
+	return nil
+}
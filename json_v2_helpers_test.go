@@ -0,0 +1,16 @@
+//go:build goexperiment.jsonv2 && go1.27
+
+// The JSON v2 tests reach encoding/json/v2 through these helpers so that
+// they run against json_v2.go and json_v2_go125.go alike.
+
+package null
+
+import jsonv2 "encoding/json/v2"
+
+func marshalV2(v any) ([]byte, error) {
+	return jsonv2.Marshal(v)
+}
+
+func unmarshalV2(data []byte, v any) error {
+	return jsonv2.Unmarshal(data, v)
+}
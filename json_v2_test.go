@@ -0,0 +1,57 @@
+//go:build goexperiment.jsonv2
+
+package null
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type JSONv2Suite struct {
+	suite.Suite
+}
+
+func TestJSONv2Suite(t *testing.T) {
+	suite.Run(t, new(JSONv2Suite))
+}
+
+type jsonv2Patch struct {
+	Name  Value[string] `json:"name,omitzero"`
+	Email Value[string] `json:"email,omitzero"`
+	Age   Value[int]    `json:"age,omitzero"`
+}
+
+func (s *JSONv2Suite) TestMarshal() {
+	data, err := marshalV2(jsonv2Patch{Name: New("Alice"), Email: NewNull[string]()})
+	s.Require().NoError(err)
+	s.JSONEq(`{"name":"Alice","email":null}`, string(data))
+}
+
+func (s *JSONv2Suite) TestUnmarshal() {
+	var p jsonv2Patch
+	s.Require().NoError(unmarshalV2([]byte(`{"name":"Alice","email":null}`), &p))
+	s.Equal(New("Alice"), p.Name)
+	s.True(p.Email.IsNull())
+	s.False(p.Age.IsSet())
+}
+
+func (s *JSONv2Suite) TestUnmarshal_InvalidType() {
+	var v Value[int]
+	s.Error(unmarshalV2([]byte(`"x"`), &v))
+}
+
+func (s *JSONv2Suite) TestRoundTrip() {
+	for _, want := range []jsonv2Patch{
+		{},
+		{Name: New("Alice")},
+		{Email: NewNull[string](), Age: New(0)},
+		{Name: New(""), Email: New("a@example.com"), Age: NewNull[int]()},
+	} {
+		data, err := marshalV2(want)
+		s.Require().NoError(err)
+		var got jsonv2Patch
+		s.Require().NoError(unmarshalV2(data, &got))
+		s.Equal(want, got, string(data))
+	}
+}
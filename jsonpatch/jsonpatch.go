@@ -0,0 +1,251 @@
+// Package jsonpatch converts between RFC 6902 JSON Patch documents and patch
+// structs of null.Value fields.
+//
+// Each Value field maps to at most one operation: Unset fields produce
+// none, Null fields a "remove" and Valid fields a "replace" (or "add")
+// carrying the value. Paths are JSON Pointers (RFC 6901) built from the
+// fields' json tags, descending into nested structs.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/jsonfield"
+)
+
+// ContentType is the media type of a JSON Patch document.
+const ContentType = "application/json-patch+json"
+
+// Op is a JSON Patch operation name.
+type Op string
+
+// Operations defined by RFC 6902.
+const (
+	OpAdd     Op = "add"
+	OpRemove  Op = "remove"
+	OpReplace Op = "replace"
+	OpMove    Op = "move"
+	OpCopy    Op = "copy"
+	OpTest    Op = "test"
+)
+
+// Operation is a single JSON Patch operation.
+type Operation struct {
+	Op    Op              `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+var (
+	// ErrUnsupportedOp is returned by Parse for operations a patch struct
+	// cannot represent (move, copy and test).
+	ErrUnsupportedOp = errors.New("unsupported operation")
+
+	// ErrInvalidPath is returned by Parse for paths that do not resolve to
+	// a field of the patch struct, including array indices.
+	ErrInvalidPath = errors.New("invalid path")
+)
+
+// --- Generation ---
+
+// Option configures Generate.
+type Option func(*config)
+
+type config struct {
+	validOp Op
+}
+
+// WithValidOp sets the operation emitted for Valid fields. It must be
+// OpReplace (the default) or OpAdd; add also works when the target member
+// does not exist yet.
+func WithValidOp(op Op) Option {
+	return func(c *config) {
+		c.validOp = op
+	}
+}
+
+// Generate converts a patch struct into a list of operations, in field
+// order. Nested structs (and non-nil pointers to them) are descended into;
+// other non-Value fields are emitted as if they were Valid, unless
+// encoding/json would omit them under omitempty or omitzero.
+func Generate(patch any, opts ...Option) ([]Operation, error) {
+	c := config{validOp: OpReplace}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.validOp != OpReplace && c.validOp != OpAdd {
+		return nil, fmt.Errorf("jsonpatch: %w %q for valid fields", ErrUnsupportedOp, c.validOp)
+	}
+
+	v := reflect.ValueOf(patch)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if !jsonfield.IsStruct(v.Type()) {
+		return nil, fmt.Errorf("jsonpatch: Generate requires a struct, got %T", patch)
+	}
+	return generate(nil, &c, "", v)
+}
+
+func generate(ops []Operation, c *config, prefix string, v reflect.Value) ([]Operation, error) {
+	for _, f := range jsonfield.Fields(v.Type()) {
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue
+		}
+		path := prefix + "/" + escape(f.Name)
+
+		if bridge.IsValue(fv.Type()) {
+			switch fv.Interface().(interface{ State() null.State }).State() {
+			case null.Unset:
+				continue
+			case null.Null:
+				ops = append(ops, Operation{Op: OpRemove, Path: path})
+				continue
+			}
+		} else if fv.Kind() == reflect.Pointer && jsonfield.IsStruct(fv.Type().Elem()) {
+			if fv.IsNil() {
+				continue
+			}
+			if ops, err = generate(ops, c, path, fv.Elem()); err != nil {
+				return ops, err
+			}
+			continue
+		} else if jsonfield.IsStruct(fv.Type()) {
+			if ops, err = generate(ops, c, path, fv); err != nil {
+				return ops, err
+			}
+			continue
+		} else if f.Omit(fv) {
+			continue
+		}
+
+		value, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return ops, fmt.Errorf("jsonpatch: %s: %w", path, err)
+		}
+		ops = append(ops, Operation{Op: c.validOp, Path: path, Value: value})
+	}
+	return ops, nil
+}
+
+// --- Parsing ---
+
+// Parse decodes a JSON Patch document into the patch struct pointed to by
+// patch. add and replace set the addressed field from the operation's
+// value, remove sets it to Null. Operations are applied in order, so a
+// later operation on the same path wins.
+//
+// Operations that a patch struct cannot represent are rejected:
+// move, copy and test with ErrUnsupportedOp, and paths into arrays or
+// unknown members with ErrInvalidPath.
+func Parse(doc []byte, patch any) error {
+	v := reflect.ValueOf(patch)
+	if v.Kind() != reflect.Pointer || v.IsNil() || !jsonfield.IsStruct(v.Elem().Type()) {
+		return fmt.Errorf("jsonpatch: Parse requires a non-nil pointer to a struct, got %T", patch)
+	}
+	var ops []Operation
+	if err := json.Unmarshal(doc, &ops); err != nil {
+		return fmt.Errorf("jsonpatch: %w", err)
+	}
+	for i, op := range ops {
+		if err := parseOp(v.Elem(), op); err != nil {
+			return fmt.Errorf("jsonpatch: operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return nil
+}
+
+func parseOp(root reflect.Value, op Operation) error {
+	switch op.Op {
+	case OpAdd, OpReplace, OpRemove:
+	case OpMove, OpCopy, OpTest:
+		return ErrUnsupportedOp
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrUnsupportedOp, op.Op)
+	}
+
+	fv, err := resolve(root, op.Path)
+	if err != nil {
+		return err
+	}
+
+	value := op.Value
+	if op.Op == OpRemove {
+		if !bridge.IsValue(fv.Type()) {
+			return fmt.Errorf("%w: cannot remove %s", ErrInvalidPath, fv.Type())
+		}
+		value = json.RawMessage("null")
+	} else if value == nil {
+		return errors.New("missing value")
+	}
+
+	target := fv.Addr().Interface()
+	if !bridge.IsValue(fv.Type()) {
+		// A nested patch struct: start from scratch so the operation
+		// replaces it rather than merging into it.
+		fv.SetZero()
+	}
+	return json.Unmarshal(value, target)
+}
+
+// resolve walks the JSON Pointer path from root and returns the field it
+// addresses, allocating nil nested patch pointers along the way.
+func resolve(root reflect.Value, path string) (reflect.Value, error) {
+	if path == "" {
+		return root, fmt.Errorf("%w: the whole document cannot be patched", ErrInvalidPath)
+	}
+	if !strings.HasPrefix(path, "/") {
+		return root, fmt.Errorf("%w: %q does not start with /", ErrInvalidPath, path)
+	}
+
+	v := root
+	for _, seg := range strings.Split(path[1:], "/") {
+		seg = unescape(seg)
+		switch {
+		case bridge.IsValue(v.Type()) || !jsonfield.IsStruct(v.Type()):
+			if _, err := strconv.Atoi(seg); err == nil || seg == "-" {
+				return v, fmt.Errorf("%w: array indices are not supported", ErrInvalidPath)
+			}
+			return v, fmt.Errorf("%w: cannot address %q inside %s", ErrInvalidPath, seg, v.Type())
+		}
+
+		f, ok := jsonfield.Lookup(jsonfield.Fields(v.Type()), seg)
+		if !ok {
+			return v, fmt.Errorf("%w: no member %q", ErrInvalidPath, seg)
+		}
+		fv, err := jsonfield.ByIndexAlloc(v, f.Index)
+		if err != nil {
+			return v, err
+		}
+		if fv.Kind() == reflect.Pointer && jsonfield.IsStruct(fv.Type().Elem()) {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		v = fv
+	}
+	return v, nil
+}
+
+// --- JSON Pointers ---
+
+var (
+	escaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	unescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func escape(s string) string   { return escaper.Replace(s) }
+func unescape(s string) string { return unescaper.Replace(s) }
@@ -0,0 +1,236 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type addressPatch struct {
+	Street null.Value[string] `json:"street"`
+	City   null.Value[string] `json:"city"`
+}
+
+type userPatch struct {
+	Name    null.Value[string]   `json:"name"`
+	Email   null.Value[string]   `json:"email"`
+	Tags    null.Value[[]string] `json:"tags"`
+	Odd     null.Value[int]      `json:"a/b~c"`
+	Address addressPatch         `json:"address"`
+	Billing *addressPatch        `json:"billing"`
+	Skipped null.Value[string]   `json:"-"`
+}
+
+// --- Generate Tests ---
+
+type GenerateSuite struct {
+	suite.Suite
+}
+
+func TestGenerateSuite(t *testing.T) {
+	suite.Run(t, new(GenerateSuite))
+}
+
+func (s *GenerateSuite) TestOperations() {
+	p := userPatch{
+		Name:    null.New("Alice"),
+		Email:   null.NewNull[string](),
+		Address: addressPatch{City: null.New("Rome")},
+		Billing: &addressPatch{Street: null.NewNull[string]()},
+		Skipped: null.New("x"),
+	}
+	ops, err := Generate(p)
+	s.Require().NoError(err)
+	s.Equal([]Operation{
+		{Op: OpReplace, Path: "/name", Value: json.RawMessage(`"Alice"`)},
+		{Op: OpRemove, Path: "/email"},
+		{Op: OpReplace, Path: "/address/city", Value: json.RawMessage(`"Rome"`)},
+		{Op: OpRemove, Path: "/billing/street"},
+	}, ops)
+}
+
+func (s *GenerateSuite) TestMarshaledDocument() {
+	ops, err := Generate(&userPatch{Name: null.New("Alice"), Email: null.NewNull[string]()})
+	s.Require().NoError(err)
+	doc, err := json.Marshal(ops)
+	s.Require().NoError(err)
+	s.JSONEq(`[{"op":"replace","path":"/name","value":"Alice"},{"op":"remove","path":"/email"}]`, string(doc))
+}
+
+func (s *GenerateSuite) TestWithValidOp() {
+	ops, err := Generate(userPatch{Name: null.New("Alice")}, WithValidOp(OpAdd))
+	s.Require().NoError(err)
+	s.Equal([]Operation{{Op: OpAdd, Path: "/name", Value: json.RawMessage(`"Alice"`)}}, ops)
+
+	_, err = Generate(userPatch{}, WithValidOp(OpMove))
+	s.ErrorIs(err, ErrUnsupportedOp)
+}
+
+func (s *GenerateSuite) TestEscapesPointer() {
+	ops, err := Generate(userPatch{Odd: null.New(1)})
+	s.Require().NoError(err)
+	s.Require().Len(ops, 1)
+	s.Equal("/a~1b~0c", ops[0].Path)
+}
+
+func (s *GenerateSuite) TestEmpty() {
+	ops, err := Generate(userPatch{})
+	s.Require().NoError(err)
+	s.Empty(ops)
+
+	ops, err = Generate((*userPatch)(nil))
+	s.Require().NoError(err)
+	s.Empty(ops)
+
+	_, err = Generate("patch")
+	s.Error(err)
+}
+
+func (s *GenerateSuite) TestOmitOptions() {
+	type patch struct {
+		Name  null.Value[string] `json:"name"`
+		Note  string             `json:"note,omitempty"`
+		Count int                `json:"count,omitzero"`
+		Flag  bool               `json:"flag"`
+	}
+	ops, err := Generate(patch{Name: null.New("Alice")})
+	s.Require().NoError(err)
+	s.Equal([]Operation{
+		{Op: OpReplace, Path: "/name", Value: json.RawMessage(`"Alice"`)},
+		{Op: OpReplace, Path: "/flag", Value: json.RawMessage(`false`)},
+	}, ops)
+
+	ops, err = Generate(patch{Note: "hi", Count: 2})
+	s.Require().NoError(err)
+	s.Equal([]Operation{
+		{Op: OpReplace, Path: "/note", Value: json.RawMessage(`"hi"`)},
+		{Op: OpReplace, Path: "/count", Value: json.RawMessage(`2`)},
+		{Op: OpReplace, Path: "/flag", Value: json.RawMessage(`false`)},
+	}, ops)
+}
+
+// --- Parse Tests ---
+
+type ParseSuite struct {
+	suite.Suite
+}
+
+func TestParseSuite(t *testing.T) {
+	suite.Run(t, new(ParseSuite))
+}
+
+func (s *ParseSuite) TestOperations() {
+	doc := `[
+		{"op":"replace","path":"/name","value":"Alice"},
+		{"op":"remove","path":"/email"},
+		{"op":"add","path":"/tags","value":["a","b"]},
+		{"op":"replace","path":"/a~1b~0c","value":7},
+		{"op":"add","path":"/billing/city","value":"Rome"}
+	]`
+	var p userPatch
+	s.Require().NoError(Parse([]byte(doc), &p))
+	s.Equal(null.New("Alice"), p.Name)
+	s.True(p.Email.IsNull())
+	s.Equal([]string{"a", "b"}, p.Tags.Get())
+	s.Equal(7, p.Odd.Get())
+	s.False(p.Address.City.IsSet())
+	s.Require().NotNil(p.Billing)
+	s.Equal(null.New("Rome"), p.Billing.City)
+	s.False(p.Billing.Street.IsSet())
+}
+
+func (s *ParseSuite) TestReplaceNestedObject() {
+	var p userPatch
+	p.Address.Street = null.New("Main St")
+	doc := `[{"op":"replace","path":"/address","value":{"city":null}}]`
+	s.Require().NoError(Parse([]byte(doc), &p))
+	s.False(p.Address.Street.IsSet())
+	s.True(p.Address.City.IsNull())
+}
+
+func (s *ParseSuite) TestRoundTrip() {
+	want := userPatch{
+		Name:    null.New("Alice"),
+		Email:   null.NewNull[string](),
+		Address: addressPatch{Street: null.New("Main St")},
+	}
+	ops, err := Generate(want)
+	s.Require().NoError(err)
+	doc, err := json.Marshal(ops)
+	s.Require().NoError(err)
+
+	var got userPatch
+	s.Require().NoError(Parse(doc, &got))
+	s.Equal(want, got)
+}
+
+func (s *ParseSuite) TestShadowedEmbeddedFields() {
+	type Audit struct {
+		Name null.Value[string] `json:"name"`
+		By   null.Value[string] `json:"by"`
+	}
+	type patch struct {
+		Audit
+		Name null.Value[string] `json:"name"`
+	}
+	var p patch
+	s.Require().NoError(Parse([]byte(`[{"op":"replace","path":"/name","value":"top"}]`), &p))
+	s.Equal(patch{Name: null.New("top")}, p)
+
+	ops, err := Generate(patch{Audit: Audit{Name: null.New("hidden"), By: null.New("ops")}})
+	s.Require().NoError(err)
+	s.Equal([]Operation{{Op: OpReplace, Path: "/by", Value: json.RawMessage(`"ops"`)}}, ops)
+}
+
+func (s *ParseSuite) TestUnsupportedOps() {
+	for _, doc := range []string{
+		`[{"op":"move","from":"/name","path":"/email"}]`,
+		`[{"op":"copy","from":"/name","path":"/email"}]`,
+		`[{"op":"test","path":"/name","value":"Alice"}]`,
+		`[{"op":"frobnicate","path":"/name"}]`,
+	} {
+		var p userPatch
+		s.ErrorIs(Parse([]byte(doc), &p), ErrUnsupportedOp, doc)
+	}
+}
+
+func (s *ParseSuite) TestInvalidPaths() {
+	for _, doc := range []string{
+		`[{"op":"add","path":"/tags/0","value":"a"}]`,
+		`[{"op":"add","path":"/tags/-","value":"a"}]`,
+		`[{"op":"add","path":"/name/first","value":"a"}]`,
+		`[{"op":"add","path":"/unknown","value":"a"}]`,
+		`[{"op":"add","path":"/Skipped","value":"a"}]`,
+		`[{"op":"add","path":"","value":{}}]`,
+		`[{"op":"add","path":"name","value":"a"}]`,
+		`[{"op":"remove","path":"/address"}]`,
+	} {
+		var p userPatch
+		s.ErrorIs(Parse([]byte(doc), &p), ErrInvalidPath, doc)
+	}
+}
+
+func (s *ParseSuite) TestErrorNamesOperation() {
+	var p userPatch
+	err := Parse([]byte(`[{"op":"remove","path":"/name"},{"op":"move","from":"/a","path":"/b"}]`), &p)
+	s.EqualError(err, "jsonpatch: operation 1 (move /b): unsupported operation")
+}
+
+func (s *ParseSuite) TestBadInput() {
+	var p userPatch
+	s.Error(Parse([]byte(`{`), &p))
+	s.Error(Parse([]byte(`[{"op":"add","path":"/name"}]`), &p))
+	s.Error(Parse([]byte(`[{"op":"add","path":"/name","value":1}]`), &p))
+	s.Error(Parse([]byte(`[]`), p))
+}
+
+func TestPointerEscaping(t *testing.T) {
+	for _, s := range []string{"plain", "a/b", "a~b", "~1", "/~"} {
+		require.Equal(t, s, unescape(escape(s)))
+	}
+	assert.Equal(t, "~01", escape("~1"))
+}
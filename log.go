@@ -0,0 +1,61 @@
+package null
+
+import "log/slog"
+
+// Redacted is logged in place of the value of a Valid Value wrapped with
+// Redact, or of an attribute hidden by RedactAttrs.
+const Redacted = "<redacted>"
+
+// LogValue implements slog.LogValuer. A Valid Value logs as its inner value,
+// itself resolved if it is a slog.LogValuer; a Null Value logs as nil
+// (null in JSON output). An Unset Value logs as an empty group, which
+// handlers omit.
+func (v Value[T]) LogValue() slog.Value {
+	switch v.state {
+	case Valid:
+		return slog.AnyValue(v.v).Resolve()
+	case Null:
+		return slog.AnyValue(nil)
+	default:
+		return slog.GroupValue()
+	}
+}
+
+// Attr returns a slog.Attr for v. Unset Values produce an attribute that
+// handlers omit.
+func Attr[T any](key string, v Value[T]) slog.Attr {
+	return slog.Attr{Key: key, Value: v.LogValue()}
+}
+
+// Redact returns a slog.LogValuer for v that hides a Valid value behind
+// Redacted but still shows whether v was Null or Unset:
+//
+//	slog.Info("login", "password", null.Redact(req.Password))
+func Redact[T any](v Value[T]) slog.LogValuer {
+	return redacted(v.state)
+}
+
+type redacted State
+
+func (r redacted) LogValue() slog.Value {
+	if State(r) == Valid {
+		return slog.StringValue(Redacted)
+	}
+	return Value[struct{}]{state: State(r)}.LogValue()
+}
+
+// RedactAttrs returns a function for slog.HandlerOptions.ReplaceAttr that
+// logs attributes whose key match reports true for as Redacted. Null values
+// still log as nil and Unset values are still omitted, so the state shows:
+//
+//	slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+//		ReplaceAttr: null.RedactAttrs(func(key string) bool { return key == "password" }),
+//	}))
+func RedactAttrs(match func(key string) bool) func(groups []string, a slog.Attr) slog.Attr {
+	return func(_ []string, a slog.Attr) slog.Attr {
+		if a.Value.Kind() == slog.KindAny && a.Value.Any() == nil || !match(a.Key) {
+			return a
+		}
+		return slog.String(a.Key, Redacted)
+	}
+}
@@ -0,0 +1,135 @@
+package null
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type logUser struct{ Name string }
+
+func (u logUser) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", u.Name))
+}
+
+func TestLogValue(t *testing.T) {
+	tests := map[string]struct {
+		v    slog.LogValuer
+		kind slog.Kind
+		want any
+	}{
+		"valid string": {New("Alice"), slog.KindString, "Alice"},
+		"valid int":    {New(42), slog.KindInt64, int64(42)},
+		"valid bool":   {New(true), slog.KindBool, true},
+		"null":         {NewNull[string](), slog.KindAny, nil},
+		"unset":        {Value[string]{}, slog.KindGroup, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tt.v.LogValue()
+			assert.Equal(t, tt.kind, got.Kind())
+			if tt.kind == slog.KindGroup {
+				assert.Empty(t, got.Group())
+				return
+			}
+			assert.Equal(t, tt.want, got.Any())
+		})
+	}
+}
+
+type LogSuite struct {
+	suite.Suite
+	buf    bytes.Buffer
+	logger *slog.Logger
+}
+
+func TestLogSuite(t *testing.T) {
+	suite.Run(t, new(LogSuite))
+}
+
+func (s *LogSuite) SetupTest() {
+	s.buf.Reset()
+	s.logger = slog.New(slog.NewJSONHandler(&s.buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func (s *LogSuite) logged() map[string]any {
+	var m map[string]any
+	s.Require().NoError(json.Unmarshal(s.buf.Bytes(), &m))
+	delete(m, slog.MessageKey)
+	return m
+}
+
+func (s *LogSuite) TestJSONHandler() {
+	s.logger.Info("req",
+		"name", New("Alice"),
+		"email", NewNull[string](),
+		"age", Value[int]{},
+	)
+	s.Equal(map[string]any{"name": "Alice", "email": nil}, s.logged())
+}
+
+func (s *LogSuite) TestResolvesNestedLogValuer() {
+	s.logger.Info("req", "user", New(logUser{Name: "Alice"}))
+	s.Equal(map[string]any{"user": map[string]any{"name": "Alice"}}, s.logged())
+}
+
+func (s *LogSuite) TestAttr() {
+	s.logger.LogAttrs(s.T().Context(), slog.LevelInfo, "req",
+		Attr("name", New("Alice")),
+		Attr("email", NewNull[string]()),
+		Attr("age", Value[int]{}),
+	)
+	s.Equal(map[string]any{"name": "Alice", "email": nil}, s.logged())
+}
+
+func (s *LogSuite) TestRedact() {
+	s.logger.Info("login",
+		"password", Redact(New("hunter2")),
+		"token", Redact(NewNull[string]()),
+		"otp", Redact(Value[string]{}),
+	)
+	s.Equal(map[string]any{"password": "<redacted>", "token": nil}, s.logged())
+}
+
+func (s *LogSuite) TestRedactAttrs() {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: RedactAttrs(func(key string) bool { return strings.Contains(key, "password") }),
+	}))
+	logger.LogAttrs(s.T().Context(), slog.LevelInfo, "login",
+		Attr("user", New("alice")),
+		Attr("password", New("hunter2")),
+		Attr("old_password", NewNull[string]()),
+		Attr("new_password", Value[string]{}),
+		slog.String("password_hint", "pet"),
+	)
+
+	var m map[string]any
+	s.Require().NoError(json.Unmarshal(buf.Bytes(), &m))
+	s.Equal("alice", m["user"])
+	s.Equal(Redacted, m["password"])
+	s.Equal(Redacted, m["password_hint"])
+	s.Contains(m, "old_password")
+	s.Nil(m["old_password"])
+	s.NotContains(m, "new_password")
+}
+
+func (s *LogSuite) TestTextHandler() {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("req", "name", New("Alice"), "age", Value[int]{})
+	s.Contains(buf.String(), "name=Alice")
+	s.NotContains(buf.String(), "age")
+}
@@ -0,0 +1,278 @@
+// Package mergepatch implements RFC 7386 JSON Merge Patch on top of
+// null.Value.
+//
+// A merge patch maps directly onto the three states of a Value: an absent
+// member leaves the target untouched (Unset), a null member deletes it
+// (Null) and any other member replaces it (Valid). Objects are merged
+// recursively.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/jsonfield"
+)
+
+// ContentType is the media type of a JSON Merge Patch document.
+const ContentType = "application/merge-patch+json"
+
+// --- Documents ---
+
+// Apply applies the merge patch to the JSON document doc and returns the
+// resulting document, as specified by RFC 7386 section 2.
+func Apply(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("mergepatch: invalid document: %w", err)
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("mergepatch: invalid patch: %w", err)
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+// Merge combines two merge patches into one that has the same effect as
+// applying a and then b.
+//
+// The one case a single patch cannot express is a sets a member to null or
+// a non-object and b patches the same member with an object. The combined
+// member is then b's object, nulls included; applied to a target where that
+// member is an object, it merges into it, so members b does not name
+// survive where applying a and then b would have dropped them.
+func Merge(a, b []byte) ([]byte, error) {
+	pa, err := decode(a)
+	if err != nil {
+		return nil, fmt.Errorf("mergepatch: invalid patch: %w", err)
+	}
+	pb, err := decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("mergepatch: invalid patch: %w", err)
+	}
+	return json.Marshal(mergePatches(pa, pb))
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after top-level value")
+	}
+	return v, nil
+}
+
+// mergeValue is the MergePatch function of RFC 7386.
+func mergeValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergeValue(t[k], v)
+	}
+	return t
+}
+
+func mergePatches(a, b any) any {
+	pb, ok := b.(map[string]any)
+	if !ok {
+		return b
+	}
+	pa, ok := a.(map[string]any)
+	if !ok {
+		return pb
+	}
+	for k, v := range pb {
+		if cur, ok := pa[k]; ok {
+			pa[k] = mergePatches(cur, v)
+			continue
+		}
+		pa[k] = v
+	}
+	return pa
+}
+
+// --- Structs ---
+
+// ApplyTo applies the merge patch to the Go value pointed to by dst.
+//
+// Struct members are matched to fields the same way encoding/json matches
+// them. Nested objects are merged into nested structs field by field, so
+// fields the patch does not mention keep their values, including ones
+// that have no JSON representation. A null member resets its field to
+// the zero value, which for a Value is Null. Any other member is decoded
+// into a fresh value that replaces the field; objects patching maps or
+// Values are merged with the field's current JSON form first.
+func ApplyTo(dst any, patch []byte) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("mergepatch: ApplyTo requires a non-nil pointer, got %T", dst)
+	}
+	if !json.Valid(patch) {
+		return fmt.Errorf("mergepatch: invalid patch")
+	}
+	return applyValue(v.Elem(), bytes.TrimSpace(patch))
+}
+
+func applyValue(v reflect.Value, raw []byte) error {
+	if len(raw) == 0 || raw[0] != '{' {
+		return replace(v, raw)
+	}
+
+	t := v.Type()
+	switch {
+	case jsonfield.IsStruct(t):
+		return applyStruct(v, raw)
+	case t.Kind() == reflect.Pointer && jsonfield.IsStruct(t.Elem()):
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return applyStruct(v.Elem(), raw)
+	}
+
+	cur, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	merged, err := Apply(cur, raw)
+	if err != nil {
+		return err
+	}
+	return replace(v, merged)
+}
+
+func replace(v reflect.Value, raw []byte) error {
+	fresh := reflect.New(v.Type())
+	if err := json.Unmarshal(raw, fresh.Interface()); err != nil {
+		return fmt.Errorf("mergepatch: %w", err)
+	}
+	v.Set(fresh.Elem())
+	return nil
+}
+
+func applyStruct(v reflect.Value, raw []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return fmt.Errorf("mergepatch: %w", err)
+	}
+	fields := jsonfield.Fields(v.Type())
+	for name, member := range members {
+		f, ok := jsonfield.LookupFold(fields, name)
+		if !ok {
+			continue
+		}
+		fv, err := jsonfield.ByIndexAlloc(v, f.Index)
+		if err != nil {
+			return fmt.Errorf("mergepatch: %w", err)
+		}
+		if err := applyValue(fv, member); err != nil {
+			return fmt.Errorf("mergepatch: member %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// --- Generation ---
+
+// Generate builds a merge patch document from a patch struct.
+//
+// Value fields contribute according to their state: Unset fields are
+// omitted, Null fields become null members and Valid fields hold their
+// JSON encoding. Nested structs are generated recursively and omitted
+// when empty. Other fields are encoded as encoding/json would, honoring
+// omitempty and omitzero.
+func Generate(patch any) ([]byte, error) {
+	v := reflect.ValueOf(patch)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return []byte("{}"), nil
+		}
+		v = v.Elem()
+	}
+	if !jsonfield.IsStruct(v.Type()) {
+		return nil, fmt.Errorf("mergepatch: Generate requires a struct, got %T", patch)
+	}
+	var buf bytes.Buffer
+	if _, err := generateStruct(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// generateStruct writes v as a JSON object and reports how many members
+// it wrote.
+func generateStruct(buf *bytes.Buffer, v reflect.Value) (int, error) {
+	buf.WriteByte('{')
+	n := 0
+	for _, f := range jsonfield.Fields(v.Type()) {
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue
+		}
+
+		var member []byte
+		switch {
+		case bridge.IsValue(fv.Type()):
+			switch fv.Interface().(interface{ State() null.State }).State() {
+			case null.Unset:
+				continue
+			case null.Null:
+				member = []byte("null")
+			default:
+				if member, err = json.Marshal(fv.Interface()); err != nil {
+					return n, err
+				}
+			}
+		case jsonfield.IsStruct(fv.Type()) || fv.Kind() == reflect.Pointer && jsonfield.IsStruct(fv.Type().Elem()):
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			var nested bytes.Buffer
+			count, err := generateStruct(&nested, fv)
+			if err != nil {
+				return n, err
+			}
+			if count == 0 {
+				continue
+			}
+			member = nested.Bytes()
+		case f.Omit(fv):
+			continue
+		default:
+			if member, err = json.Marshal(fv.Interface()); err != nil {
+				return n, err
+			}
+		}
+
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(member)
+		n++
+	}
+	buf.WriteByte('}')
+	return n, nil
+}
@@ -0,0 +1,342 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// --- Conformance Tests ---
+
+// TestApply_RFC7386 runs the examples from RFC 7386 Appendix A.
+func TestApply_RFC7386(t *testing.T) {
+	tests := map[string]struct {
+		doc, patch, want string
+	}{
+		"replace member":       {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		"add member":           {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		"delete member":        {`{"a":"b"}`, `{"a":null}`, `{}`},
+		"delete one of two":    {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		"array to string":      {`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		"string to array":      {`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		"nested object":        {`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		"array replaced":       {`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		"arrays":               {`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		"object to array":      {`{"a":"b"}`, `["c"]`, `["c"]`},
+		"null patch":           {`{"a":"foo"}`, `null`, `null`},
+		"string patch":         {`{"a":"foo"}`, `"bar"`, `"bar"`},
+		"null in target kept":  {`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		"array target":         {`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		"nested nulls dropped": {`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestApply_PreservesLargeNumbers(t *testing.T) {
+	got, err := Apply([]byte(`{"id":9007199254740993}`), []byte(`{"a":1}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":9007199254740993,"a":1}`, string(got))
+}
+
+func TestApply_InvalidJSON(t *testing.T) {
+	_, err := Apply([]byte(`{`), []byte(`{}`))
+	assert.Error(t, err)
+	_, err = Apply([]byte(`{}`), []byte(`{} {}`))
+	assert.Error(t, err)
+}
+
+// --- Merge Tests ---
+
+func TestMerge(t *testing.T) {
+	tests := map[string]struct {
+		a, b, want string
+	}{
+		"disjoint":        {`{"a":1}`, `{"b":2}`, `{"a":1,"b":2}`},
+		"later wins":      {`{"a":1}`, `{"a":2}`, `{"a":2}`},
+		"null kept":       {`{"a":1}`, `{"a":null}`, `{"a":null}`},
+		"nested":          {`{"a":{"b":1,"c":null}}`, `{"a":{"c":3}}`, `{"a":{"b":1,"c":3}}`},
+		"object replaces": {`{"a":{"b":1}}`, `{"a":"x"}`, `{"a":"x"}`},
+		"non-object b":    {`{"a":1}`, `[1]`, `[1]`},
+		"after deletion":  {`{"a":null}`, `{"a":{"b":1,"c":null}}`, `{"a":{"b":1,"c":null}}`},
+		"after scalar":    {`{"a":1}`, `{"a":{"b":{"c":null}}}`, `{"a":{"b":{"c":null}}}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Merge([]byte(tt.a), []byte(tt.b))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestMerge_EquivalentToSequentialApply(t *testing.T) {
+	doc := []byte(`{"a":{"b":1,"c":2},"d":"x","e":[1]}`)
+	a := []byte(`{"a":{"b":null},"d":"y"}`)
+	b := []byte(`{"a":{"c":3,"f":4},"e":null}`)
+
+	step, err := Apply(doc, a)
+	require.NoError(t, err)
+	sequential, err := Apply(step, b)
+	require.NoError(t, err)
+
+	merged, err := Merge(a, b)
+	require.NoError(t, err)
+	combined, err := Apply(doc, merged)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, string(sequential), string(combined))
+}
+
+func TestMerge_ObjectAfterDeletion(t *testing.T) {
+	a := []byte(`{"a":null}`)
+	b := []byte(`{"a":{"b":1,"c":null}}`)
+	merged, err := Merge(a, b)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		doc, want string
+	}{
+		"member absent":   {`{}`, `{"a":{"b":1}}`},
+		"member scalar":   {`{"a":"x"}`, `{"a":{"b":1}}`},
+		"members b names": {`{"a":{"b":0,"c":5}}`, `{"a":{"b":1}}`},
+		"members b omits": {`{"a":{"d":5}}`, `{"a":{"b":1,"d":5}}`}, // sequentially {"a":{"b":1}}
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), merged)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+// --- Struct Tests ---
+
+type address struct {
+	Street string  `json:"street"`
+	City   *string `json:"city"`
+}
+
+type user struct {
+	Name    string             `json:"name"`
+	Email   null.Value[string] `json:"email"`
+	Tags    []string           `json:"tags"`
+	Labels  map[string]string  `json:"labels"`
+	Address address            `json:"address"`
+	Billing *address           `json:"billing,omitempty"`
+	Secret  string             `json:"-"`
+	Meta
+}
+
+type Meta struct {
+	Version int `json:"version"`
+}
+
+type ApplyToSuite struct {
+	suite.Suite
+}
+
+func TestApplyToSuite(t *testing.T) {
+	suite.Run(t, new(ApplyToSuite))
+}
+
+func (s *ApplyToSuite) user() user {
+	city := "Paris"
+	return user{
+		Name:    "Alice",
+		Email:   null.New("alice@example.com"),
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core", "tier": "1"},
+		Address: address{Street: "Main St", City: &city},
+		Secret:  "keep",
+		Meta:    Meta{Version: 1},
+	}
+}
+
+func (s *ApplyToSuite) TestAbsentMembersUntouched() {
+	u := s.user()
+	s.Require().NoError(ApplyTo(&u, []byte(`{}`)))
+	s.Equal(s.user().Name, u.Name)
+	s.Equal("keep", u.Secret)
+}
+
+func (s *ApplyToSuite) TestReplaceAndDelete() {
+	u := s.user()
+	err := ApplyTo(&u, []byte(`{"name":"Bob","email":null,"tags":["c"],"version":2}`))
+	s.Require().NoError(err)
+	s.Equal("Bob", u.Name)
+	s.True(u.Email.IsNull())
+	s.Equal([]string{"c"}, u.Tags)
+	s.Equal(2, u.Version)
+	s.Equal("keep", u.Secret)
+}
+
+func (s *ApplyToSuite) TestNestedStruct() {
+	u := s.user()
+	s.Require().NoError(ApplyTo(&u, []byte(`{"address":{"city":null}}`)))
+	s.Equal("Main St", u.Address.Street)
+	s.Nil(u.Address.City)
+}
+
+func (s *ApplyToSuite) TestNestedPointerAllocated() {
+	u := s.user()
+	s.Require().NoError(ApplyTo(&u, []byte(`{"billing":{"street":"Side St","city":null}}`)))
+	s.Require().NotNil(u.Billing)
+	s.Equal("Side St", u.Billing.Street)
+}
+
+func (s *ApplyToSuite) TestMapMerged() {
+	u := s.user()
+	s.Require().NoError(ApplyTo(&u, []byte(`{"labels":{"tier":null,"env":"prod"}}`)))
+	s.Equal(map[string]string{"team": "core", "env": "prod"}, u.Labels)
+}
+
+func (s *ApplyToSuite) TestValueOfStructMerged() {
+	type doc struct {
+		Address null.Value[address] `json:"address"`
+	}
+	d := doc{Address: null.New(address{Street: "Main St"})}
+	s.Require().NoError(ApplyTo(&d, []byte(`{"address":{"city":"Rome"}}`)))
+	s.Equal("Main St", d.Address.Get().Street)
+	s.Equal("Rome", *d.Address.Get().City)
+}
+
+func (s *ApplyToSuite) TestCaseInsensitiveMatch() {
+	u := s.user()
+	s.Require().NoError(ApplyTo(&u, []byte(`{"NAME":"Carol"}`)))
+	s.Equal("Carol", u.Name)
+}
+
+func (s *ApplyToSuite) TestShadowedEmbeddedFields() {
+	type Base struct {
+		Name  string `json:"name"`
+		Email string
+		ID    int
+	}
+	type Other struct {
+		ID int
+	}
+	type Tagged struct {
+		Email string `json:"Email"`
+	}
+	type doc struct {
+		Base
+		Other
+		Tagged
+		Name string `json:"name"`
+	}
+
+	// The shallower name, the tagged Email and neither ID are the ones
+	// encoding/json would use.
+	var want, got doc
+	patch := []byte(`{"name":"top","Email":"tagged","ID":7}`)
+	s.Require().NoError(json.Unmarshal(patch, &want))
+	s.Require().NoError(ApplyTo(&got, patch))
+	s.Equal(want, got)
+	s.Equal(doc{Name: "top", Tagged: Tagged{Email: "tagged"}}, got)
+
+	gen, err := Generate(struct {
+		Base
+		Name null.Value[string] `json:"name"`
+	}{Base: Base{Name: "hidden"}, Name: null.New("shown")})
+	s.Require().NoError(err)
+	s.JSONEq(`{"name":"shown","Email":"","ID":0}`, string(gen))
+}
+
+func (s *ApplyToSuite) TestTypeError() {
+	u := s.user()
+	s.Error(ApplyTo(&u, []byte(`{"name":42}`)))
+}
+
+func (s *ApplyToSuite) TestInvalidArguments() {
+	var u user
+	s.Error(ApplyTo(u, []byte(`{}`)))
+	s.Error(ApplyTo(&u, []byte(`{`)))
+}
+
+// --- Generate Tests ---
+
+type addressPatch struct {
+	Street null.Value[string] `json:"street"`
+	City   null.Value[string] `json:"city"`
+}
+
+type userPatch struct {
+	Name      null.Value[string]    `json:"name"`
+	Email     null.Value[string]    `json:"email"`
+	Age       null.Value[int]       `json:"age"`
+	Birthday  null.Value[time.Time] `json:"birthday"`
+	Address   addressPatch          `json:"address"`
+	Billing   *addressPatch         `json:"billing"`
+	Untouched addressPatch          `json:"untouched"`
+}
+
+func TestGenerate(t *testing.T) {
+	p := userPatch{
+		Name:     null.New("Alice"),
+		Email:    null.NewNull[string](),
+		Birthday: null.New(time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)),
+		Address:  addressPatch{City: null.NewNull[string]()},
+		Billing:  &addressPatch{Street: null.New("Side St")},
+	}
+	got, err := Generate(p)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{"name":"Alice","email":null,"birthday":"2000-01-02T00:00:00Z","address":{"city":null},"billing":{"street":"Side St"}}`,
+		string(got))
+}
+
+func TestGenerate_OmitOptions(t *testing.T) {
+	type doc struct {
+		Name  null.Value[string] `json:"name"`
+		Note  string             `json:"note,omitempty"`
+		Count int                `json:"count,omitzero"`
+		At    time.Time          `json:"at,omitzero"`
+		Tags  []string           `json:"tags,omitempty"`
+		Kept  string             `json:"kept"`
+	}
+	got, err := Generate(doc{Name: null.New("Alice")})
+	require.NoError(t, err)
+	want, err := json.Marshal(doc{Name: null.New("Alice")})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(got))
+	assert.JSONEq(t, `{"name":"Alice","kept":""}`, string(got))
+
+	got, err = Generate(doc{Note: "n", Count: 2, Tags: []string{"a"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"note":"n","count":2,"tags":["a"],"kept":""}`, string(got))
+}
+
+func TestGenerate_Empty(t *testing.T) {
+	got, err := Generate(&userPatch{})
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(got))
+
+	got, err = Generate((*userPatch)(nil))
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(got))
+
+	_, err = Generate(42)
+	assert.Error(t, err)
+}
+
+func TestGenerate_RoundTripThroughUnmarshal(t *testing.T) {
+	p := userPatch{Name: null.New("Alice"), Email: null.NewNull[string]()}
+	doc, err := Generate(p)
+	require.NoError(t, err)
+
+	var got userPatch
+	require.NoError(t, ApplyTo(&got, doc))
+	assert.Equal(t, p, got)
+}
@@ -0,0 +1,714 @@
+package nullcbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/codec"
+)
+
+var errEOF = errors.New("nullcbor: unexpected end of data")
+
+// Unmarshal decodes the single CBOR data item in data into the value v
+// points to. null and undefined leave values other than null.Value
+// untouched, except that pointers, maps, slices and interfaces are set to
+// nil. Decoding into an empty interface produces bool, uint64 (or int64 for
+// negative integers), float64, string, []byte, time.Time, []any and
+// map[string]any (or map[any]any when a key is not text).
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("nullcbor: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+	d := &decoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.off != len(d.data) {
+		return errors.New("nullcbor: unexpected data after top-level item")
+	}
+	return nil
+}
+
+type decoder struct {
+	data  []byte
+	off   int
+	depth codec.Depth
+}
+
+func (d *decoder) enter() error {
+	if err := d.depth.Enter(); err != nil {
+		return fmt.Errorf("nullcbor: %w", err)
+	}
+	return nil
+}
+
+func (d *decoder) leave() { d.depth.Leave() }
+
+func (d *decoder) peek() (byte, error) {
+	if d.off >= len(d.data) {
+		return 0, errEOF
+	}
+	return d.data[d.off], nil
+}
+
+// head reads the initial byte of a data item and its argument: the value
+// of an integer, the length of a string, array or map, the tag number, or
+// the bits of a float. indefinite reports an indefinite-length item.
+func (d *decoder) head() (major byte, n uint64, indefinite bool, err error) {
+	ib, err := d.peek()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	d.off++
+	major, ai := ib>>5, ib&0x1f
+	switch {
+	case ai < 24:
+		return major, uint64(ai), false, nil
+	case ai <= 27:
+		size := 1 << (ai - 24)
+		if len(d.data)-d.off < size {
+			return 0, 0, false, errEOF
+		}
+		p := d.data[d.off : d.off+size]
+		d.off += size
+		switch size {
+		case 1:
+			n = uint64(p[0])
+		case 2:
+			n = uint64(binary.BigEndian.Uint16(p))
+		case 4:
+			n = uint64(binary.BigEndian.Uint32(p))
+		default:
+			n = binary.BigEndian.Uint64(p)
+		}
+		return major, n, false, nil
+	case ai == 31 && major >= majorBytes && major <= majorMap:
+		return major, 0, true, nil
+	}
+	return 0, 0, false, fmt.Errorf("nullcbor: invalid initial byte 0x%02x", ib)
+}
+
+// more reports whether the array or map being read has an i-th item,
+// consuming the break code that ends an indefinite-length item.
+func (d *decoder) more(indefinite bool, n, i uint64) (bool, error) {
+	if !indefinite {
+		return i < n, nil
+	}
+	ib, err := d.peek()
+	if err != nil {
+		return false, err
+	}
+	if ib == cborBreak {
+		d.off++
+		return false, nil
+	}
+	return true, nil
+}
+
+// checkLen rejects a length of n items that cannot fit in the rest of the
+// data.
+func (d *decoder) checkLen(n uint64) error {
+	if !codec.Fits(n, d.data[d.off:]) {
+		return errEOF
+	}
+	return nil
+}
+
+// readString reads the contents of a byte or text string whose head has
+// been read, joining the chunks of an indefinite-length string.
+func (d *decoder) readString(major byte, n uint64, indefinite bool) ([]byte, error) {
+	var s []byte
+	if !indefinite {
+		if err := d.checkLen(n); err != nil {
+			return nil, err
+		}
+		s = d.data[d.off : d.off+int(n)]
+		d.off += int(n)
+	} else {
+		s = []byte{}
+		for {
+			ib, err := d.peek()
+			if err != nil {
+				return nil, err
+			}
+			if ib == cborBreak {
+				d.off++
+				break
+			}
+			m, cn, ci, err := d.head()
+			if err != nil {
+				return nil, err
+			}
+			if m != major || ci {
+				return nil, errors.New("nullcbor: invalid chunk in indefinite-length string")
+			}
+			chunk, err := d.readString(m, cn, false)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, chunk...)
+		}
+	}
+	if major == majorText && !utf8.Valid(s) {
+		return nil, errors.New("nullcbor: invalid UTF-8 in text string")
+	}
+	return s, nil
+}
+
+// --- Typed decoding ---
+
+// decode decodes the next data item into the addressable v.
+func (d *decoder) decode(v reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	ib, err := d.peek()
+	if err != nil {
+		return err
+	}
+	t := v.Type()
+
+	if bridge.IsValue(t) {
+		switch ib {
+		case cborNull:
+			d.off++
+			bridge.Set(v, reflect.Value{})
+			return nil
+		case cborUndefined:
+			d.off++
+			v.SetZero()
+			return nil
+		}
+		return codec.DecodeValue(v, d.decode)
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		start := d.off
+		if err := d.skip(); err != nil {
+			return err
+		}
+		return v.Addr().Interface().(Unmarshaler).UnmarshalCBOR(d.data[start:d.off])
+	}
+
+	if ib == cborNull || ib == cborUndefined {
+		d.off++
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			v.SetZero()
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return d.decode(v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			if !v.IsNil() && v.Elem().Kind() == reflect.Pointer {
+				return d.decode(v.Elem().Elem())
+			}
+			return fmt.Errorf("nullcbor: cannot decode into %s", t)
+		}
+		x, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+	if t == timeType {
+		return d.decodeTime(v)
+	}
+
+	major, n, indefinite, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case majorUint:
+		return setUint(v, n)
+	case majorNegInt:
+		return setNegInt(v, n)
+	case majorBytes, majorText:
+		s, err := d.readString(major, n, indefinite)
+		if err != nil {
+			return err
+		}
+		return setString(v, major, s)
+	case majorArray:
+		return d.decodeArray(v, n, indefinite)
+	case majorMap:
+		return d.decodeMap(v, n, indefinite)
+	case majorTag:
+		// Tags other than the time tags carry no meaning for Go values, so
+		// the tagged item is decoded as is.
+		return d.decode(v)
+	}
+
+	switch ib {
+	case cborFalse, cborTrue:
+		if v.Kind() != reflect.Bool {
+			return typeError("bool", t)
+		}
+		v.SetBool(ib == cborTrue)
+		return nil
+	case cborFloat16, cborFloat32, cborFloat64:
+		if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+			return typeError("float", t)
+		}
+		v.SetFloat(toFloat(ib, n))
+		return nil
+	}
+	return typeError("simple value", t)
+}
+
+func (d *decoder) decodeArray(v reflect.Value, n uint64, indefinite bool) error {
+	if err := d.checkLen(n); err != nil {
+		return err
+	}
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(t, 0, int(n))
+		for i := uint64(0); ; i++ {
+			ok, err := d.more(indefinite, n, i)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+			s = reflect.Append(s, reflect.Zero(t.Elem()))
+			if err := d.decode(s.Index(int(i))); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Array:
+		i := 0
+		for ; ; i++ {
+			ok, err := d.more(indefinite, n, uint64(i))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+			if i >= v.Len() {
+				if err := d.skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		for ; i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+		return nil
+	}
+	return typeError("array", t)
+}
+
+func (d *decoder) decodeMap(v reflect.Value, n uint64, indefinite bool) error {
+	if err := d.checkLen(n); err != nil {
+		return err
+	}
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := codec.Fields(t, "cbor")
+		for i := uint64(0); ; i++ {
+			ok, err := d.more(indefinite, n, i)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+			key, isText, err := d.readKey()
+			if err != nil {
+				return err
+			}
+			fv, found, err := codec.StructField(v, fields, key)
+			if err != nil {
+				return fmt.Errorf("nullcbor: %w", err)
+			}
+			if !isText || !found {
+				if err := d.skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.decode(fv); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		for i := uint64(0); ; i++ {
+			ok, err := d.more(indefinite, n, i)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+			k := reflect.New(t.Key()).Elem()
+			if err := d.decode(k); err != nil {
+				return err
+			}
+			e := reflect.New(t.Elem()).Elem()
+			if err := d.decode(e); err != nil {
+				return err
+			}
+			if err := codec.SetMapIndex(v, k, e); err != nil {
+				return fmt.Errorf("nullcbor: %w", err)
+			}
+		}
+	}
+	return typeError("map", t)
+}
+
+// readKey reads a struct key. Keys that are not text strings are skipped
+// and reported with isText false.
+func (d *decoder) readKey() (key string, isText bool, err error) {
+	ib, err := d.peek()
+	if err != nil {
+		return "", false, err
+	}
+	if ib>>5 != majorText {
+		return "", false, d.skip()
+	}
+	_, n, indefinite, err := d.head()
+	if err != nil {
+		return "", false, err
+	}
+	s, err := d.readString(majorText, n, indefinite)
+	return string(s), true, err
+}
+
+// decodeTime decodes a tag 0 date/time string or a tag 1 epoch number into
+// v. The untagged forms are accepted too.
+func (d *decoder) decodeTime(v reflect.Value) error {
+	tag, tagged := uint64(0), false
+	if ib, _ := d.peek(); ib>>5 == majorTag {
+		_, n, _, err := d.head()
+		if err != nil {
+			return err
+		}
+		if n != tagDateTime && n != tagEpoch {
+			return fmt.Errorf("nullcbor: cannot decode tag %d into time.Time", n)
+		}
+		tag, tagged = n, true
+	}
+
+	x, err := d.decodeAny()
+	if err != nil {
+		return err
+	}
+	var t time.Time
+	switch x := x.(type) {
+	case string:
+		if tagged && tag != tagDateTime {
+			return typeError("tag 1 string", timeType)
+		}
+		if t, err = time.Parse(time.RFC3339Nano, x); err != nil {
+			return fmt.Errorf("nullcbor: %w", err)
+		}
+	case uint64, int64, float64:
+		if tagged && tag != tagEpoch {
+			return typeError("tag 0 number", timeType)
+		}
+		if t, err = epochTime(x); err != nil {
+			return err
+		}
+	default:
+		return typeError(fmt.Sprintf("%T", x), timeType)
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func epochTime(x any) (time.Time, error) {
+	switch x := x.(type) {
+	case uint64:
+		if x > math.MaxInt64 {
+			return time.Time{}, errors.New("nullcbor: epoch time out of range")
+		}
+		return time.Unix(int64(x), 0).UTC(), nil
+	case int64:
+		return time.Unix(x, 0).UTC(), nil
+	}
+	f := x.(float64)
+	if math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) > math.MaxInt64 {
+		return time.Time{}, errors.New("nullcbor: epoch time out of range")
+	}
+	sec, frac := math.Modf(f)
+	// A float64 holds epoch times to about a microsecond.
+	return time.Unix(int64(sec), int64(frac*1e9)).Round(time.Microsecond).UTC(), nil
+}
+
+func setUint(v reflect.Value, n uint64) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n > math.MaxInt64 || v.OverflowInt(int64(n)) {
+			return overflowError(fmt.Sprint(n), v.Type())
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.OverflowUint(n) {
+			return overflowError(fmt.Sprint(n), v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(n))
+	default:
+		return typeError("unsigned integer", v.Type())
+	}
+	return nil
+}
+
+// setNegInt stores the negative integer -1-n in v.
+func setNegInt(v reflect.Value, n uint64) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n > math.MaxInt64 || v.OverflowInt(-1-int64(n)) {
+			return overflowError("-1-"+fmt.Sprint(n), v.Type())
+		}
+		v.SetInt(-1 - int64(n))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(-1 - float64(n))
+	default:
+		return typeError("negative integer", v.Type())
+	}
+	return nil
+}
+
+// setString stores a byte or text string in a string, []byte or [N]byte.
+func setString(v reflect.Value, major byte, s []byte) error {
+	t := v.Type()
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(string(s))
+		return nil
+	case v.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		v.SetBytes(bytes.Clone(s))
+		return nil
+	case v.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8:
+		if len(s) != v.Len() {
+			return fmt.Errorf("nullcbor: cannot decode %d bytes into %s", len(s), t)
+		}
+		reflect.Copy(v, reflect.ValueOf(s))
+		return nil
+	}
+	if major == majorText {
+		return typeError("text string", t)
+	}
+	return typeError("byte string", t)
+}
+
+// --- Untyped decoding ---
+
+// decodeAny decodes the next data item into the Go value for an empty
+// interface, as described on Unmarshal.
+func (d *decoder) decodeAny() (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	start := d.off
+	ib, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+	major, n, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUint:
+		return n, nil
+	case majorNegInt:
+		if n > math.MaxInt64 {
+			return nil, overflowError("-1-"+fmt.Sprint(n), reflect.TypeFor[int64]())
+		}
+		return -1 - int64(n), nil
+	case majorBytes:
+		s, err := d.readString(major, n, indefinite)
+		return bytes.Clone(s), err
+	case majorText:
+		s, err := d.readString(major, n, indefinite)
+		return string(s), err
+	case majorArray:
+		if err := d.checkLen(n); err != nil {
+			return nil, err
+		}
+		out := make([]any, 0, n)
+		for i := uint64(0); ; i++ {
+			ok, err := d.more(indefinite, n, i)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return out, nil
+			}
+			x, err := d.decodeAny()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, x)
+		}
+	case majorMap:
+		return d.decodeAnyMap(n, indefinite)
+	case majorTag:
+		if n == tagDateTime || n == tagEpoch {
+			d.off = start
+			t := reflect.New(timeType).Elem()
+			if err := d.decodeTime(t); err != nil {
+				return nil, err
+			}
+			return t.Interface(), nil
+		}
+		return d.decodeAny()
+	}
+
+	switch ib {
+	case cborFalse, cborTrue:
+		return ib == cborTrue, nil
+	case cborNull, cborUndefined:
+		return nil, nil
+	case cborFloat16, cborFloat32, cborFloat64:
+		return toFloat(ib, n), nil
+	}
+	return nil, fmt.Errorf("nullcbor: unsupported simple value 0x%02x", ib)
+}
+
+func (d *decoder) decodeAnyMap(n uint64, indefinite bool) (any, error) {
+	if err := d.checkLen(n); err != nil {
+		return nil, err
+	}
+	var keys, values []any
+	for i := uint64(0); ; i++ {
+		ok, err := d.more(indefinite, n, i)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		k, err := d.decodeAny()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decodeAny()
+		if err != nil {
+			return nil, err
+		}
+		keys, values = append(keys, k), append(values, v)
+	}
+
+	m, err := codec.AnyMap(keys, values)
+	if err != nil {
+		return nil, fmt.Errorf("nullcbor: %w", err)
+	}
+	return m, nil
+}
+
+// skip advances past the next data item.
+func (d *decoder) skip() error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	major, n, indefinite, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch major {
+	case majorBytes, majorText:
+		_, err := d.readString(major, n, indefinite)
+		return err
+	case majorArray, majorMap:
+		if err := d.checkLen(n); err != nil {
+			return err
+		}
+		if major == majorMap {
+			n *= 2
+		}
+		for i := uint64(0); ; i++ {
+			ok, err := d.more(indefinite, n, i)
+			if err != nil || !ok {
+				return err
+			}
+			if err := d.skip(); err != nil {
+				return err
+			}
+		}
+	case majorTag:
+		return d.skip()
+	}
+	return nil
+}
+
+// toFloat converts the bits of a half, single or double precision float,
+// as read by head, to a float64.
+func toFloat(ib byte, bits uint64) float64 {
+	switch ib {
+	case cborFloat16:
+		return halfToFloat(uint16(bits))
+	case cborFloat32:
+		return float64(math.Float32frombits(uint32(bits)))
+	}
+	return math.Float64frombits(bits)
+}
+
+func halfToFloat(h uint16) float64 {
+	exp, frac := int(h>>10)&0x1f, float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(frac, -24)
+	case 0x1f:
+		f = math.Inf(1)
+		if frac != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(frac+0x400, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+func typeError(what string, t reflect.Type) error {
+	return fmt.Errorf("nullcbor: cannot decode %s into %s", what, t)
+}
+
+func overflowError(n string, t reflect.Type) error {
+	return fmt.Errorf("nullcbor: %s overflows %s", n, t)
+}
@@ -0,0 +1,330 @@
+package nullcbor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// unhex decodes a data item written in hex, as in Appendix A of RFC 8949.
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestUnmarshal_States(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want null.Value[string]
+	}{
+		"absent":     {"a0", null.Value[string]{}},
+		"undefined":  {"a166646576696365f7", null.Value[string]{}},
+		"null":       {"a166646576696365f6", null.NewNull[string]()},
+		"value":      {"a1666465766963656161", null.New("a")},
+		"empty":      {"a16664657669636560", null.New("")},
+		"other case": {"a1664445564943456161", null.New("a")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var r reading
+			require.NoError(t, Unmarshal(unhex(t, tt.data), &r))
+			assert.Equal(t, tt.want, r.Device)
+		})
+	}
+}
+
+// TestUnmarshal_RFC decodes examples from Appendix A of RFC 8949 into an
+// empty interface.
+func TestUnmarshal_RFC(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want any
+	}{
+		"0":                  {"00", uint64(0)},
+		"1000000000000":      {"1b000000e8d4a51000", uint64(1000000000000)},
+		"-1":                 {"20", int64(-1)},
+		"-1000":              {"3903e7", int64(-1000)},
+		"half zero":          {"f90000", 0.0},
+		"half one":           {"f93c00", 1.0},
+		"half 1.5":           {"f93e00", 1.5},
+		"half max":           {"f97bff", 65504.0},
+		"half subnormal":     {"f90001", 5.960464477539063e-8},
+		"half smallest norm": {"f90400", 0.00006103515625},
+		"half -4":            {"f9c400", -4.0},
+		"half infinity":      {"f97c00", math.Inf(1)},
+		"half -infinity":     {"f9fc00", math.Inf(-1)},
+		"single":             {"fa47c35000", 100000.0},
+		"single max":         {"fa7f7fffff", 3.4028234663852886e+38},
+		"double":             {"fb3ff199999999999a", 1.1},
+		"false":              {"f4", false},
+		"null":               {"f6", nil},
+		"undefined":          {"f7", nil},
+		"bytes":              {"4401020304", []byte{1, 2, 3, 4}},
+		"text":               {"62c3bc", "ü"},
+		"array":              {"8301820203820405", []any{uint64(1), []any{uint64(2), uint64(3)}, []any{uint64(4), uint64(5)}}},
+		"map":                {"a26161016162820203", map[string]any{"a": uint64(1), "b": []any{uint64(2), uint64(3)}}},
+		"int keys":           {"a201020304", map[any]any{uint64(1): uint64(2), uint64(3): uint64(4)}},
+		"date time":          {"c074323031332d30332d32315432303a30343a30305a", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		"epoch":              {"c11a514b67b0", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		"epoch float":        {"c1fb41d452d9ec200000", time.Date(2013, 3, 21, 20, 4, 0, 5e8, time.UTC)},
+		"other tag":          {"d74401020304", []byte{1, 2, 3, 4}},
+		"indefinite bytes":   {"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		"indefinite text":    {"7f657374726561646d696e67ff", "streaming"},
+		"indefinite empty":   {"9fff", []any{}},
+		"indefinite nested":  {"9f018202039f0405ffff", []any{uint64(1), []any{uint64(2), uint64(3)}, []any{uint64(4), uint64(5)}}},
+		"indefinite map":     {"bf6346756ef563416d7421ff", map[string]any{"Fun": true, "Amt": int64(-2)}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got any
+			require.NoError(t, Unmarshal(unhex(t, tt.data), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		into any
+		want string
+	}{
+		"empty":            {"", new(any), "unexpected end of data"},
+		"truncated head":   {"19", new(any), "unexpected end of data"},
+		"truncated text":   {"6461", new(string), "unexpected end of data"},
+		"huge array":       {"9bffffffffffffffff", new([]int), "unexpected end of data"},
+		"trailing":         {"0000", new(int), "unexpected data after top-level item"},
+		"reserved info":    {"1c", new(any), "invalid initial byte 0x1c"},
+		"lone break":       {"ff", new(any), "invalid initial byte 0xff"},
+		"indefinite int":   {"1f", new(int), "invalid initial byte 0x1f"},
+		"bad chunk":        {"5f6161ff", new([]byte), "invalid chunk"},
+		"bad utf8":         {"61ff", new(string), "invalid UTF-8"},
+		"type mismatch":    {"6161", new(int), "cannot decode text string into int"},
+		"negative uint":    {"20", new(uint), "cannot decode negative integer into uint"},
+		"overflow":         {"190100", new(int8), "256 overflows int8"},
+		"neg overflow":     {"3b8000000000000000", new(int64), "overflows int64"},
+		"float into int":   {"f93c00", new(int), "cannot decode float into int"},
+		"bool into text":   {"f5", new(string), "cannot decode bool into string"},
+		"array length":     {"4401020304", new([2]byte), "cannot decode 4 bytes into [2]uint8"},
+		"bad time":         {"c06161", new(time.Time), "parsing time"},
+		"time tag":         {"c249010000000000000000", new(time.Time), "cannot decode tag 2 into time.Time"},
+		"epoch string":     {"c16161", new(time.Time), "cannot decode tag 1 string"},
+		"unhashable key":   {"a14101f5", new(any), "unhashable map key of type []uint8"},
+		"unhashable into":  {"a18001", new(map[any]int), "unhashable map key"},
+		"unhashable field": {"a1a161588001", new(map[struct{ X any }]int), "unhashable map key of type struct { X interface {} }"},
+		"into value":       {"6161", new(null.Value[int]), "cannot decode text string into int"},
+		"not a pointer":    {"00", 0, "requires a non-nil pointer"},
+		"nil pointer":      {"00", (*int)(nil), "requires a non-nil pointer"},
+		"unmarshaler":      {"fbc072c00000000000", new(celsius), "below absolute zero"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, Unmarshal(unhex(t, tt.data), tt.into), tt.want)
+		})
+	}
+}
+
+type DecodeSuite struct {
+	suite.Suite
+}
+
+func TestDecodeSuite(t *testing.T) {
+	suite.Run(t, new(DecodeSuite))
+}
+
+func (s *DecodeSuite) TestRoundTrip() {
+	in := reading{
+		Device: null.New("probe-1"),
+		Temp:   null.NewNull[float64](),
+		Count:  null.New(-3),
+		Raw:    null.New([]byte{0xca, 0xfe}),
+		At:     null.New(time.Date(2024, 1, 15, 10, 0, 0, 123, time.FixedZone("", 3600))),
+		Where:  null.New(location{Lat: null.New(48.85), Lon: null.NewNull[float64]()}),
+		Tags:   []null.Value[string]{null.New("a"), null.NewNull[string](), {}},
+		Labels: map[string]null.Value[string]{"room": null.New("lab"), "rack": null.NewNull[string](), "row": {}},
+		Plain:  "x",
+		Meta:   Meta{Firmware: null.New("1.2")},
+	}
+	b, err := Marshal(in)
+	s.Require().NoError(err)
+
+	var out reading
+	s.Require().NoError(Unmarshal(b, &out))
+	s.True(in.At.Get().Equal(out.At.Get()))
+	out.At = in.At
+	s.Equal(in, out)
+}
+
+type base struct {
+	ID null.Value[int] `cbor:"id"`
+}
+
+type Kind struct {
+	Kind null.Value[string] `cbor:"kind"`
+}
+
+func (s *DecodeSuite) TestEmbedded() {
+	// Like encoding/json, unexported embedded structs and embedded struct
+	// pointers are flattened.
+	type doc struct {
+		base
+		*Kind
+		Name null.Value[string] `cbor:"name"`
+	}
+	in := doc{base: base{ID: null.New(3)}, Kind: &Kind{Kind: null.New("a")}, Name: null.New("x")}
+	b, err := Marshal(in)
+	s.Require().NoError(err)
+
+	var got map[string]any
+	s.Require().NoError(Unmarshal(b, &got))
+	s.Equal(map[string]any{"id": uint64(3), "kind": "a", "name": "x"}, got)
+
+	var out doc
+	s.Require().NoError(Unmarshal(b, &out))
+	s.Equal(in, out)
+
+	// A nil embedded pointer contributes no keys.
+	b, err = Marshal(doc{Name: null.New("x")})
+	s.Require().NoError(err)
+	got = nil
+	s.Require().NoError(Unmarshal(b, &got))
+	s.Equal(map[string]any{"name": "x"}, got)
+}
+
+func (s *DecodeSuite) TestNestedPointersAndSlices() {
+	type doc struct {
+		Where  *location              `cbor:"where"`
+		Grid   [][]null.Value[int]    `cbor:"grid"`
+		Fixed  [2]null.Value[int]     `cbor:"fixed"`
+		Maybe  null.Value[*location]  `cbor:"maybe"`
+		Nested null.Value[[]location] `cbor:"nested"`
+	}
+	in := doc{
+		Where:  &location{Lat: null.NewNull[float64]()},
+		Grid:   [][]null.Value[int]{{null.New(1), {}}, {null.NewNull[int]()}},
+		Fixed:  [2]null.Value[int]{null.New(1)},
+		Maybe:  null.New(&location{Lon: null.New(2.0)}),
+		Nested: null.New([]location{{Lat: null.New(1.0)}, {}}),
+	}
+	b, err := Marshal(in)
+	s.Require().NoError(err)
+
+	var out doc
+	s.Require().NoError(Unmarshal(b, &out))
+	s.Equal(in, out)
+}
+
+func (s *DecodeSuite) TestNullIntoPlainValues() {
+	n, p, m := 5, new(int), map[string]int{"a": 1}
+	s.Require().NoError(Unmarshal(unhex(s.T(), "f6"), &n))
+	s.Require().NoError(Unmarshal(unhex(s.T(), "f7"), &p))
+	s.Require().NoError(Unmarshal(unhex(s.T(), "f6"), &m))
+	s.Equal(5, n)
+	s.Nil(p)
+	s.Nil(m)
+}
+
+func (s *DecodeSuite) TestUnknownKeys() {
+	// {"x": [1, {"y": h''}], 1: 2, "device": "a"}
+	var r reading
+	s.Require().NoError(Unmarshal(unhex(s.T(), "a361788201a16179400102666465766963656161"), &r))
+	s.Equal(null.New("a"), r.Device)
+}
+
+func (s *DecodeSuite) TestArrayLengths() {
+	var short [1]int
+	s.Require().NoError(Unmarshal(unhex(s.T(), "83010203"), &short))
+	s.Equal([1]int{1}, short)
+
+	long := [3]int{7, 8, 9}
+	s.Require().NoError(Unmarshal(unhex(s.T(), "9f01ff"), &long))
+	s.Equal([3]int{1, 0, 0}, long)
+}
+
+func (s *DecodeSuite) TestTimeForms() {
+	want := time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)
+	for _, data := range []string{
+		"c074323031332d30332d32315432303a30343a30305a",
+		"74323031332d30332d32315432303a30343a30305a",
+		"c11a514b67b0",
+		"1a514b67b0",
+		"c1fb41d452d9ec000000",
+	} {
+		var v null.Value[time.Time]
+		s.Require().NoError(Unmarshal(unhex(s.T(), data), &v), data)
+		s.True(want.Equal(v.Get()), data)
+	}
+
+	at := time.Date(2024, 1, 15, 10, 0, 0, 123456000, time.UTC)
+	b, err := Marshal(at, WithEpochTime())
+	s.Require().NoError(err)
+	var got time.Time
+	s.Require().NoError(Unmarshal(b, &got))
+	s.Equal(at, got)
+}
+
+func (s *DecodeSuite) TestUnmarshaler() {
+	var v null.Value[celsius]
+	s.Require().NoError(Unmarshal(unhex(s.T(), "f93e00"), &v))
+	s.Equal(null.New(celsius(1.5)), v)
+}
+
+func (s *DecodeSuite) TestInterfaceWithPointer() {
+	var name nameStringer
+	var st fmt.Stringer = &name
+	s.Require().NoError(Unmarshal(unhex(s.T(), "6161"), &st))
+	s.Equal(nameStringer("a"), name)
+
+	var empty fmt.Stringer
+	s.ErrorContains(Unmarshal(unhex(s.T(), "6161"), &empty), "cannot decode into fmt.Stringer")
+}
+
+type nameStringer string
+
+func (n *nameStringer) String() string { return string(*n) }
+
+func (s *DecodeSuite) TestDepthLimit() {
+	data := make([]byte, codec.MaxDepth+1)
+	for i := range data {
+		data[i] = 0x81
+	}
+	var v any
+	s.ErrorContains(Unmarshal(append(data, 0), &v), "exceeded max nesting depth")
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, s := range []string{
+		"a166646576696365f6",
+		"9f018202039f0405ffff",
+		"bf6346756ef563416d7421ff",
+		"c074323031332d30332d32315432303a30343a30305a",
+		"5f42010243030405ff",
+		"a1a161588001",
+	} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var r reading
+		if Unmarshal(data, &r) == nil {
+			b, err := Marshal(r)
+			require.NoError(t, err)
+			var again reading
+			require.NoError(t, Unmarshal(b, &again))
+		}
+		var v any
+		_ = Unmarshal(data, &v)
+		var m map[struct{ X any }]int
+		_ = Unmarshal(data, &m)
+	})
+}
@@ -0,0 +1,228 @@
+package nullcbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/codec"
+)
+
+// Option configures Marshal.
+type Option func(*encoder)
+
+// WithEpochTime writes time.Time as a tag 1 epoch number instead of a tag 0
+// RFC 3339 string: an integer number of seconds, or a float when the time
+// has a fractional second (which keeps about microsecond precision). The
+// location is not kept.
+func WithEpochTime() Option {
+	return func(e *encoder) { e.epochTime = true }
+}
+
+type encoder struct {
+	epochTime bool
+	depth     codec.Depth
+}
+
+// Marshal returns the CBOR encoding of v.
+func Marshal(v any, opts ...Option) ([]byte, error) {
+	e := &encoder{}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e.encode(nil, reflect.ValueOf(v))
+}
+
+func (e *encoder) encode(b []byte, v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return append(b, cborNull), nil
+	}
+	if err := e.depth.Enter(); err != nil {
+		return nil, fmt.Errorf("nullcbor: %w", err)
+	}
+	defer e.depth.Leave()
+
+	t := v.Type()
+	if bridge.IsValue(t) {
+		switch state, x := codec.Value(v); state {
+		case null.Unset:
+			return append(b, cborUndefined), nil
+		case null.Null:
+			return append(b, cborNull), nil
+		default:
+			return e.encode(b, x)
+		}
+	}
+	if t.Implements(marshalerType) {
+		if (t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface) && v.IsNil() {
+			return append(b, cborNull), nil
+		}
+		return appendMarshaler(b, v.Interface().(Marshaler))
+	}
+	if v.CanAddr() && reflect.PointerTo(t).Implements(marshalerType) {
+		return appendMarshaler(b, v.Addr().Interface().(Marshaler))
+	}
+	if t == timeType {
+		return e.encodeTime(b, v.Interface().(time.Time)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, cborTrue), nil
+		}
+		return append(b, cborFalse), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendInt(b, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendHead(b, majorUint, v.Uint()), nil
+	case reflect.Float32:
+		b = append(b, cborFloat32)
+		return binary.BigEndian.AppendUint32(b, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		b = append(b, cborFloat64)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		b = appendHead(b, majorText, uint64(v.Len()))
+		return append(b, v.String()...), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return append(b, cborNull), nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			b = appendHead(b, majorBytes, uint64(v.Len()))
+			return append(b, v.Bytes()...), nil
+		}
+		return e.encodeArray(b, v)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			b = appendHead(b, majorBytes, uint64(v.Len()))
+			for i := range v.Len() {
+				b = append(b, byte(v.Index(i).Uint()))
+			}
+			return b, nil
+		}
+		return e.encodeArray(b, v)
+	case reflect.Map:
+		if v.IsNil() {
+			return append(b, cborNull), nil
+		}
+		return e.encodeMap(b, v)
+	case reflect.Struct:
+		return e.encodeStruct(b, v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return append(b, cborNull), nil
+		}
+		return e.encode(b, v.Elem())
+	}
+	return nil, fmt.Errorf("nullcbor: unsupported type %s", t)
+}
+
+func (e *encoder) encodeTime(b []byte, t time.Time) []byte {
+	if !e.epochTime {
+		b = appendHead(b, majorTag, tagDateTime)
+		s := t.Format(time.RFC3339Nano)
+		b = appendHead(b, majorText, uint64(len(s)))
+		return append(b, s...)
+	}
+	b = appendHead(b, majorTag, tagEpoch)
+	if t.Nanosecond() == 0 {
+		return appendInt(b, t.Unix())
+	}
+	b = append(b, cborFloat64)
+	return binary.BigEndian.AppendUint64(b, math.Float64bits(float64(t.Unix())+float64(t.Nanosecond())/1e9))
+}
+
+func (e *encoder) encodeArray(b []byte, v reflect.Value) ([]byte, error) {
+	b = appendHead(b, majorArray, uint64(v.Len()))
+	for i := range v.Len() {
+		var err error
+		if b, err = e.encode(b, v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// encodeMap writes the entries of v sorted by the bytes of their encoded
+// keys, the deterministic order of RFC 8949 section 4.2.1.
+func (e *encoder) encodeMap(b []byte, v reflect.Value) ([]byte, error) {
+	type entry struct{ key, value []byte }
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := e.encode(nil, iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		value, err := e.encode(nil, iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key, value})
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return bytes.Compare(a.key, b.key) })
+
+	b = appendHead(b, majorMap, uint64(len(entries)))
+	for _, en := range entries {
+		b = append(append(b, en.key...), en.value...)
+	}
+	return b, nil
+}
+
+// encodeStruct writes v as a map keyed by field name, in field order.
+// Unset Values and empty omitempty fields are left out.
+func (e *encoder) encodeStruct(b []byte, v reflect.Value) ([]byte, error) {
+	var body []byte
+	n := 0
+	for name, fv := range codec.StructFields(v, "cbor") {
+		body = appendHead(body, majorText, uint64(len(name)))
+		body = append(body, name...)
+		var err error
+		if body, err = e.encode(body, fv); err != nil {
+			return nil, err
+		}
+		n++
+	}
+	b = appendHead(b, majorMap, uint64(n))
+	return append(b, body...), nil
+}
+
+func appendMarshaler(b []byte, m Marshaler) ([]byte, error) {
+	data, err := m.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+	return append(b, data...), nil
+}
+
+// appendHead writes the initial byte of a data item of the given major type
+// with argument n in its shortest form.
+func appendHead(b []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(b, m|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, m|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, m|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, m|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, m|27), n)
+}
+
+func appendInt(b []byte, i int64) []byte {
+	if i < 0 {
+		return appendHead(b, majorNegInt, uint64(^i))
+	}
+	return appendHead(b, majorUint, uint64(i))
+}
@@ -0,0 +1,211 @@
+package nullcbor
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type location struct {
+	Lat null.Value[float64] `cbor:"lat"`
+	Lon null.Value[float64] `cbor:"lon"`
+}
+
+type Meta struct {
+	Firmware null.Value[string] `cbor:"fw"`
+}
+
+type reading struct {
+	Device   null.Value[string]            `cbor:"device"`
+	Temp     null.Value[float64]           `cbor:"temp"`
+	Count    null.Value[int]               `cbor:"count"`
+	Raw      null.Value[[]byte]            `cbor:"raw"`
+	At       null.Value[time.Time]         `cbor:"at"`
+	Where    null.Value[location]          `cbor:"where"`
+	Tags     []null.Value[string]          `cbor:"tags,omitempty"`
+	Labels   map[string]null.Value[string] `cbor:"labels,omitempty"`
+	Plain    string                        `json:"plain,omitempty"`
+	Skipped  null.Value[string]            `cbor:"-"`
+	Untagged null.Value[bool]
+	Meta
+}
+
+func TestMarshal_States(t *testing.T) {
+	tests := map[string]struct {
+		v    any
+		want string
+	}{
+		"unset":        {null.Value[int]{}, "f7"},
+		"null":         {null.NewNull[int](), "f6"},
+		"valid":        {null.New(1), "01"},
+		"valid zero":   {null.New(""), "60"},
+		"nested null":  {null.New(null.NewNull[int]()), "f6"},
+		"slice":        {[]null.Value[int]{null.New(1), null.NewNull[int](), {}}, "8301f6f7"},
+		"map":          {map[string]null.Value[int]{"a": {}, "b": null.NewNull[int]()}, "a26161f76162f6"},
+		"unset field":  {location{}, "a0"},
+		"null field":   {location{Lat: null.NewNull[float64]()}, "a1636c6174f6"},
+		"nil pointer":  {(*int)(nil), "f6"},
+		"nil slice":    {[]int(nil), "f6"},
+		"empty slice":  {[]int{}, "80"},
+		"nil map":      {map[string]int(nil), "f6"},
+		"nil":          {nil, "f6"},
+		"valid struct": {null.New(location{Lat: null.New(1.5)}), "a1636c6174fb3ff8000000000000"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := Marshal(tt.v)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(b))
+		})
+	}
+}
+
+// TestMarshal_RFC checks the encodings from Appendix A of RFC 8949 that
+// this package produces in the same preferred form.
+func TestMarshal_RFC(t *testing.T) {
+	tests := map[string]struct {
+		v    any
+		want string
+	}{
+		"0":                 {0, "00"},
+		"23":                {uint8(23), "17"},
+		"24":                {24, "1818"},
+		"100":               {100, "1864"},
+		"1000":              {1000, "1903e8"},
+		"1000000":           {1000000, "1a000f4240"},
+		"1000000000000":     {int64(1000000000000), "1b000000e8d4a51000"},
+		"max uint64":        {uint64(math.MaxUint64), "1bffffffffffffffff"},
+		"-1":                {-1, "20"},
+		"-100":              {-100, "3863"},
+		"-1000":             {int16(-1000), "3903e7"},
+		"min int64":         {int64(math.MinInt64), "3b7fffffffffffffff"},
+		"1.1":               {1.1, "fb3ff199999999999a"},
+		"100000.0 float32":  {float32(100000), "fa47c35000"},
+		"false":             {false, "f4"},
+		"true":              {true, "f5"},
+		"empty bytes":       {[]byte{}, "40"},
+		"bytes":             {[]byte{1, 2, 3, 4}, "4401020304"},
+		"byte array":        {[2]byte{1, 2}, "420102"},
+		"empty text":        {"", "60"},
+		"a":                 {"a", "6161"},
+		"IETF":              {"IETF", "6449455446"},
+		"unicode":           {"ü", "62c3bc"},
+		"array":             {[]int{1, 2, 3}, "83010203"},
+		"nested arrays":     {[]any{1, []int{2, 3}, [2]int{4, 5}}, "8301820203820405"},
+		"map":               {map[int]int{3: 4, 1: 2}, "a201020304"},
+		"map sorted by key": {map[string]any{"b": []int{2, 3}, "a": 1}, "a26161016162820203"},
+		"date time": {
+			time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+			"c074323031332d30332d32315432303a30343a30305a",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := Marshal(tt.v)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(b))
+		})
+	}
+}
+
+type celsius float64
+
+func (c celsius) MarshalCBOR() ([]byte, error) {
+	if c < -273.15 {
+		return nil, errors.New("below absolute zero")
+	}
+	return Marshal(float64(c))
+}
+
+func (c *celsius) UnmarshalCBOR(data []byte) error {
+	var f float64
+	if err := Unmarshal(data, &f); err != nil {
+		return err
+	}
+	if f < -273.15 {
+		return errors.New("below absolute zero")
+	}
+	*c = celsius(f)
+	return nil
+}
+
+type EncodeSuite struct {
+	suite.Suite
+}
+
+func TestEncodeSuite(t *testing.T) {
+	suite.Run(t, new(EncodeSuite))
+}
+
+func (s *EncodeSuite) TestStruct() {
+	b, err := Marshal(reading{
+		Device:   null.New("probe-1"),
+		Temp:     null.NewNull[float64](),
+		Plain:    "x",
+		Skipped:  null.New("ignored"),
+		Untagged: null.New(true),
+		Meta:     Meta{Firmware: null.New("1.2")},
+	})
+	s.Require().NoError(err)
+
+	var got map[string]any
+	s.Require().NoError(Unmarshal(b, &got))
+	s.Equal(map[string]any{
+		"device":   "probe-1",
+		"temp":     nil,
+		"plain":    "x",
+		"Untagged": true,
+		"fw":       "1.2",
+	}, got)
+}
+
+func (s *EncodeSuite) TestOmitEmpty() {
+	b, err := Marshal(reading{Tags: []null.Value[string]{}, Labels: map[string]null.Value[string]{}})
+	s.Require().NoError(err)
+	s.Equal("a0", hex.EncodeToString(b))
+}
+
+func (s *EncodeSuite) TestEpochTime() {
+	at := time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)
+	b, err := Marshal(at, WithEpochTime())
+	s.Require().NoError(err)
+	s.Equal("c11a514b67b0", hex.EncodeToString(b))
+
+	b, err = Marshal(at.Add(500*time.Millisecond), WithEpochTime())
+	s.Require().NoError(err)
+	s.Equal("c1fb41d452d9ec200000", hex.EncodeToString(b))
+}
+
+func (s *EncodeSuite) TestMarshaler() {
+	b, err := Marshal(null.New(celsius(21.5)))
+	s.Require().NoError(err)
+	s.Equal("fb4035800000000000", hex.EncodeToString(b))
+
+	_, err = Marshal(map[string]celsius{"t": -300})
+	s.ErrorContains(err, "below absolute zero")
+
+	b, err = Marshal((*celsius)(nil))
+	s.Require().NoError(err)
+	s.Equal("f6", hex.EncodeToString(b))
+}
+
+func (s *EncodeSuite) TestErrors() {
+	_, err := Marshal(make(chan int))
+	s.ErrorContains(err, "nullcbor: unsupported type chan int")
+
+	_, err = Marshal(null.New(func() {}))
+	s.ErrorContains(err, "unsupported type func()")
+
+	type node struct{ Next any }
+	n := &node{}
+	n.Next = n
+	_, err = Marshal(n)
+	s.ErrorContains(err, "exceeded max nesting depth")
+}
@@ -0,0 +1,85 @@
+// Package nullcbor encodes and decodes CBOR (RFC 8949) with support for the
+// three states of null.Value, using only the standard library.
+//
+// Unlike JSON, CBOR has both a null and an undefined simple value, so every
+// state has its own representation on the wire:
+//
+//	null.Value[T]{}     undefined (0xf7), or omitted when it is a struct field
+//	null.NewNull[T]()   null (0xf6)
+//	null.New(x)         x encoded as T
+//
+// When decoding, a struct key that is absent or undefined leaves the field
+// Unset, null makes it Null and anything else is decoded into T:
+//
+//	type Reading struct {
+//	    Device null.Value[string]    `cbor:"device"`
+//	    Temp   null.Value[float64]   `cbor:"temp"`
+//	    At     null.Value[time.Time] `cbor:"at"`
+//	}
+//
+//	data, err := nullcbor.Marshal(r)
+//	err = nullcbor.Unmarshal(data, &r)
+//
+// Booleans, integers, floats, text and byte strings, arrays, maps, structs
+// and pointers map onto the matching CBOR types. time.Time is written as a
+// tag 0 RFC 3339 string (or a tag 1 epoch number with WithEpochTime) and
+// read from either tag. Struct fields are named by their `cbor` tag, then
+// their `json` tag, then their Go name; "-" skips a field, omitempty drops
+// empty values and, as in encoding/json, untagged embedded structs and
+// struct pointers are flattened. Map keys are sorted by their encoding, so
+// the output is deterministic. Types can control their own encoding by
+// implementing Marshaler and Unmarshaler.
+package nullcbor
+
+import (
+	"reflect"
+	"time"
+)
+
+// Marshaler is implemented by types that encode themselves into a single
+// CBOR data item.
+type Marshaler interface {
+	MarshalCBOR() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that decode themselves from a single
+// CBOR data item. The data must be copied if it is kept after returning.
+type Unmarshaler interface {
+	UnmarshalCBOR(data []byte) error
+}
+
+// Major types.
+const (
+	majorUint byte = iota
+	majorNegInt
+	majorBytes
+	majorText
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+// Simple values and float heads of major type 7, and the break stop code.
+const (
+	cborFalse     byte = 0xf4
+	cborTrue      byte = 0xf5
+	cborNull      byte = 0xf6
+	cborUndefined byte = 0xf7
+	cborFloat16   byte = 0xf9
+	cborFloat32   byte = 0xfa
+	cborFloat64   byte = 0xfb
+	cborBreak     byte = 0xff
+)
+
+// Tags for date/time strings and epoch-based dates.
+const (
+	tagDateTime = 0
+	tagEpoch    = 1
+)
+
+var (
+	timeType        = reflect.TypeFor[time.Time]()
+	marshalerType   = reflect.TypeFor[Marshaler]()
+	unmarshalerType = reflect.TypeFor[Unmarshaler]()
+)
@@ -0,0 +1,384 @@
+package nullddb
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bjaus/null/internal/bridge"
+)
+
+const defaultTagKey = "dynamodbav"
+
+// MarshalMap marshals in like attributevalue.MarshalMap, but omits the
+// attributes of Unset Values instead of writing them as NULL. Null Values
+// are still written as NULL, so the three states survive a round trip
+// through UnmarshalMap and Unset fields do not populate sparse indexes.
+//
+// Unset Values are dropped from nested structs and string-keyed maps too;
+// inside lists, where an element cannot be omitted, they remain NULL.
+//
+// Plain null.Value fields, which the attributevalue encoder would write as
+// empty maps, are encoded like Value. The decoder cannot read them back, so
+// use Value for fields that UnmarshalMap fills.
+//
+// A field's stringset, numberset or binaryset option applies to a Valid
+// Value too, so Value[[]string] tagged stringset is written as an SS. As
+// with the encoder, an empty set is written as NULL.
+//
+// The options also apply to Valid Values of types without built-in
+// conversion, such as Value[Address], which MarshalDynamoDBAttributeValue
+// encodes with the default options.
+func MarshalMap(in any, optFns ...func(*attributevalue.EncoderOptions)) (map[string]types.AttributeValue, error) {
+	var opts attributevalue.EncoderOptions
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+	e := &itemEncoder{
+		enc:      attributevalue.NewEncoder(optFns...),
+		tagKey:   cmp.Or(opts.TagKey, defaultTagKey),
+		reencode: len(optFns) > 0,
+	}
+
+	av, err := e.enc.Encode(in)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return nil, fmt.Errorf("nullddb: cannot marshal %T as a map", in)
+	}
+	if _, err := e.fix(m, reflect.ValueOf(in), ""); err != nil {
+		return nil, err
+	}
+	return m.Value, nil
+}
+
+// UnmarshalMap unmarshals m into the struct pointed to by out like
+// attributevalue.UnmarshalMap, after first resetting *out to its zero
+// value. Values whose attributes are absent from m are therefore Unset
+// rather than keeping whatever out held before.
+//
+// As with MarshalMap, the options also apply to Values of types without
+// built-in conversion.
+func UnmarshalMap(m map[string]types.AttributeValue, out any, optFns ...func(*attributevalue.DecoderOptions)) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("nullddb: UnmarshalMap requires a non-nil pointer, got %T", out)
+	}
+	v.Elem().SetZero()
+
+	dec := attributevalue.NewDecoder(optFns...)
+	av := &types.AttributeValueMemberM{Value: m}
+	if err := dec.Decode(av, out); err != nil {
+		return err
+	}
+	if len(optFns) == 0 {
+		return nil
+	}
+	var opts attributevalue.DecoderOptions
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+	d := &itemDecoder{dec: dec, tagKey: cmp.Or(opts.TagKey, defaultTagKey)}
+	return d.fix(av, v.Elem())
+}
+
+// --- Encoding ---
+
+type itemEncoder struct {
+	enc      *attributevalue.Encoder
+	tagKey   string
+	reencode bool // encode Values without built-in conversion with enc
+}
+
+// fix walks v alongside av, the attribute it was encoded to, and returns av
+// with the attributes of Unset Values deleted from its maps. Unset Values
+// are told apart by their field rather than by the NULL they encode to.
+//
+// set is the set option of the field v came from, if any. The encoder does
+// not pass it to a Value marshaling itself, so a Valid Value is encoded
+// here again as that set.
+func (e *itemEncoder) fix(av types.AttributeValue, v reflect.Value, set string) (types.AttributeValue, error) {
+	v = deref(v)
+	if v.IsValid() && bridge.IsValue(v.Type()) {
+		// The encoder writes a plain null.Value as an empty map, having
+		// no way to see its state, so it is encoded here as Value would.
+		plain := !selfMarshaling(v.Type())
+		if !v.Interface().(interface{ IsValid() bool }).IsValid() {
+			if plain {
+				return &types.AttributeValueMemberNULL{Value: true}, nil
+			}
+			return av, nil
+		}
+		v = v.MethodByName("Get").Call(nil)[0]
+		var err error
+		switch {
+		case set != "":
+			av, err = e.encodeSet(v, set)
+		case plain || e.reencode && !builtinTypes[v.Type()]:
+			av, err = e.encode(v.Interface())
+		}
+		if err != nil {
+			return nil, err
+		}
+		v = deref(v)
+	}
+	if !v.IsValid() || selfMarshaling(v.Type()) {
+		return av, nil
+	}
+
+	var err error
+	switch x := av.(type) {
+	case *types.AttributeValueMemberM:
+		switch {
+		case v.Kind() == reflect.Struct:
+			err = forEachField(v, e.tagKey, func(name, opts string, fv reflect.Value) error {
+				return e.fixEntry(x.Value, name, fv, setOption(opts))
+			})
+		case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+			iter := v.MapRange()
+			for err == nil && iter.Next() {
+				err = e.fixEntry(x.Value, iter.Key().String(), iter.Value(), "")
+			}
+		}
+	case *types.AttributeValueMemberL:
+		// The encoder can skip elements, so only walk lists that line up.
+		if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() == len(x.Value) {
+			for i := 0; err == nil && i < len(x.Value); i++ {
+				x.Value[i], err = e.fix(x.Value[i], v.Index(i), "")
+			}
+		}
+	}
+	return av, err
+}
+
+// encode encodes the value held by a Valid Value with e's options.
+func (e *itemEncoder) encode(x any) (types.AttributeValue, error) {
+	if av, ok := marshalBuiltin(x); ok {
+		return av, nil
+	}
+	av, err := e.enc.Encode(x)
+	if err != nil {
+		return nil, err
+	}
+	if av == nil {
+		return nil, fmt.Errorf("nullddb: unsupported type %T", x)
+	}
+	return av, nil
+}
+
+// encodeSet encodes v with the set option set, by way of a struct field
+// tagged with it, which is the only place the encoder reads that option.
+// Like the encoder, it writes an empty set as NULL.
+func (e *itemEncoder) encodeSet(v reflect.Value, set string) (types.AttributeValue, error) {
+	t := reflect.StructOf([]reflect.StructField{{
+		Name: "V",
+		Type: v.Type(),
+		Tag:  reflect.StructTag(e.tagKey + `:"v,` + set + `"`),
+	}})
+	s := reflect.New(t).Elem()
+	s.Field(0).Set(v)
+	av, err := e.enc.Encode(s.Interface())
+	if err != nil {
+		return nil, err
+	}
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok || m.Value["v"] == nil {
+		return nil, fmt.Errorf("nullddb: cannot marshal %s as a %s", v.Type(), set)
+	}
+	return m.Value["v"], nil
+}
+
+// fixEntry deletes the attribute name from m if v is an Unset Value and
+// fixes it otherwise.
+func (e *itemEncoder) fixEntry(m map[string]types.AttributeValue, name string, v reflect.Value, set string) error {
+	if isUnset(v) {
+		delete(m, name)
+		return nil
+	}
+	av, ok := m[name]
+	if !ok {
+		return nil
+	}
+	av, err := e.fix(av, v, set)
+	if err != nil {
+		return err
+	}
+	m[name] = av
+	return nil
+}
+
+// --- Decoding ---
+
+type itemDecoder struct {
+	dec    *attributevalue.Decoder
+	tagKey string
+}
+
+// fix walks the addressable v alongside av, the attribute it was decoded
+// from, and decodes the Valid Values without built-in conversion again
+// with d's options.
+func (d *itemDecoder) fix(av types.AttributeValue, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if bridge.IsValue(v.Type()) {
+		t := bridge.Elem(v.Type())
+		if !v.Interface().(interface{ IsValid() bool }).IsValid() || builtinTypes[t] {
+			return nil
+		}
+		x := reflect.New(t)
+		if err := d.dec.Decode(av, x.Interface()); err != nil {
+			return err
+		}
+		if err := d.fix(av, x.Elem()); err != nil {
+			return err
+		}
+		bridge.Set(v, x.Elem())
+		return nil
+	}
+	if selfMarshaling(v.Type()) {
+		return nil
+	}
+
+	switch x := av.(type) {
+	case *types.AttributeValueMemberM:
+		switch {
+		case v.Kind() == reflect.Struct:
+			return forEachField(v, d.tagKey, func(name, _ string, fv reflect.Value) error {
+				if elem, ok := x.Value[name]; ok {
+					return d.fix(elem, fv)
+				}
+				return nil
+			})
+		case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+			iter := v.MapRange()
+			for iter.Next() {
+				elem, ok := x.Value[iter.Key().String()]
+				if !ok {
+					continue
+				}
+				ev := reflect.New(v.Type().Elem()).Elem()
+				ev.Set(iter.Value())
+				if err := d.fix(elem, ev); err != nil {
+					return err
+				}
+				v.SetMapIndex(iter.Key(), ev)
+			}
+		}
+	case *types.AttributeValueMemberL:
+		if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() == len(x.Value) {
+			for i, elem := range x.Value {
+				if err := d.fix(elem, v.Index(i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// --- Fields ---
+
+var (
+	marshalerType   = reflect.TypeFor[attributevalue.Marshaler]()
+	unmarshalerType = reflect.TypeFor[attributevalue.Unmarshaler]()
+)
+
+// builtinTypes are the types that Value converts itself rather than through
+// the attributevalue encoder and decoder.
+var builtinTypes = map[reflect.Type]bool{
+	reflect.TypeFor[string]():    true,
+	reflect.TypeFor[int]():       true,
+	reflect.TypeFor[int64]():     true,
+	reflect.TypeFor[int32]():     true,
+	reflect.TypeFor[int16]():     true,
+	reflect.TypeFor[int8]():      true,
+	reflect.TypeFor[uint]():      true,
+	reflect.TypeFor[uint64]():    true,
+	reflect.TypeFor[uint32]():    true,
+	reflect.TypeFor[uint16]():    true,
+	reflect.TypeFor[uint8]():     true,
+	reflect.TypeFor[float64]():   true,
+	reflect.TypeFor[float32]():   true,
+	reflect.TypeFor[bool]():      true,
+	reflect.TypeFor[[]byte]():    true,
+	reflect.TypeFor[time.Time](): true,
+}
+
+// forEachField calls fn with the attribute name, tag options and value of
+// each field of struct v, naming fields like the attributevalue encoder: by
+// tag, then by field name, with untagged embedded structs flattened.
+func forEachField(v reflect.Value, tagKey string, fn func(name, opts string, fv reflect.Value) error) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(tagKey)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+		if sf.Anonymous && name == "" && !bridge.IsValue(sf.Type) {
+			if ev := deref(fv); ev.IsValid() && ev.Kind() == reflect.Struct {
+				if err := forEachField(ev, tagKey, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !sf.IsExported() || !fv.CanInterface() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if err := fn(name, opts, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setOption returns the set option among the tag options opts, or "".
+func setOption(opts string) string {
+	for opt := range strings.SplitSeq(opts, ",") {
+		switch opt {
+		case "stringset", "numberset", "binaryset":
+			return opt
+		}
+	}
+	return ""
+}
+
+// deref follows pointers and interfaces to the value they hold, returning
+// the zero reflect.Value for nil.
+func deref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// selfMarshaling reports whether t converts itself, so that its attributes
+// need not mirror its fields.
+func selfMarshaling(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(marshalerType) || pt.Implements(marshalerType) || pt.Implements(unmarshalerType)
+}
+
+// isUnset reports whether v is an Unset Value.
+func isUnset(v reflect.Value) bool {
+	return bridge.IsValue(v.Type()) && !v.Interface().(interface{ IsSet() bool }).IsSet()
+}
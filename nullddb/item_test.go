@@ -0,0 +1,286 @@
+package nullddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/suite"
+)
+
+type itemAddress struct {
+	City Value[string] `dynamodbav:"city"`
+	Zip  Value[string] `dynamodbav:"zip"`
+}
+
+type item struct {
+	PK      string        `dynamodbav:"pk"`
+	Name    Value[string] `dynamodbav:"name"`
+	Email   Value[string] `dynamodbav:"email"`
+	GSI     Value[string] `dynamodbav:"gsi1pk"`
+	Address itemAddress   `dynamodbav:"address"`
+	Aliases []Value[string]
+}
+
+type ItemSuite struct {
+	suite.Suite
+}
+
+func TestItemSuite(t *testing.T) {
+	suite.Run(t, new(ItemSuite))
+}
+
+func (s *ItemSuite) TestMarshalMap_OmitsUnset() {
+	av, err := MarshalMap(item{
+		PK:      "user#1",
+		Name:    New("Alice"),
+		Email:   NewNull[string](),
+		Address: itemAddress{City: New("Paris")},
+	})
+	s.Require().NoError(err)
+
+	s.Equal(&types.AttributeValueMemberS{Value: "user#1"}, av["pk"])
+	s.Equal(&types.AttributeValueMemberS{Value: "Alice"}, av["name"])
+	s.Equal(&types.AttributeValueMemberNULL{Value: true}, av["email"])
+	s.NotContains(av, "gsi1pk")
+	s.Equal(&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"city": &types.AttributeValueMemberS{Value: "Paris"},
+	}}, av["address"])
+}
+
+func (s *ItemSuite) TestMarshalMap_ListElementsStayNull() {
+	av, err := MarshalMap(item{Aliases: []Value[string]{New("a"), {}}})
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "a"},
+		&types.AttributeValueMemberNULL{Value: true},
+	}}, av["Aliases"])
+}
+
+func (s *ItemSuite) TestMarshalMap_Nested() {
+	type Audit struct {
+		UpdatedBy Value[string] `dynamodbav:"updated_by"`
+		Reason    Value[string] `dynamodbav:"reason"`
+	}
+	type doc struct {
+		Home     Value[itemAddress]       `dynamodbav:"home"`
+		Work     *itemAddress             `dynamodbav:"work"`
+		Settings map[string]Value[string] `dynamodbav:"settings"`
+		History  []itemAddress            `dynamodbav:"history"`
+		Audit
+	}
+	av, err := MarshalMap(doc{
+		Home:     New(itemAddress{City: New("Paris")}),
+		Work:     &itemAddress{Zip: NewNull[string]()},
+		Settings: map[string]Value[string]{"theme": New("dark"), "lang": {}, "tz": NewNull[string]()},
+		History:  []itemAddress{{City: New("Lyon")}},
+		Audit:    Audit{Reason: NewNull[string]()},
+	})
+	s.Require().NoError(err)
+
+	null := &types.AttributeValueMemberNULL{Value: true}
+	s.Equal(map[string]types.AttributeValue{
+		"home": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"city": &types.AttributeValueMemberS{Value: "Paris"},
+		}},
+		"work": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"zip": null}},
+		"settings": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"theme": &types.AttributeValueMemberS{Value: "dark"},
+			"tz":    null,
+		}},
+		"history": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"city": &types.AttributeValueMemberS{Value: "Lyon"},
+			}},
+		}},
+		"reason": null,
+	}, av)
+}
+
+func (s *ItemSuite) TestMarshalMap_UnsetAttributesAreNotShared() {
+	av, err := Value[string]{}.MarshalDynamoDBAttributeValue()
+	s.Require().NoError(err)
+	av.(*types.AttributeValueMemberNULL).Value = false
+
+	item, err := MarshalMap(item{Email: NewNull[string]()})
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberNULL{Value: true}, item["email"])
+	s.NotContains(item, "name")
+
+	av, err = Value[string]{}.MarshalDynamoDBAttributeValue()
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberNULL{Value: true}, av)
+}
+
+func (s *ItemSuite) TestMarshalMap_EncoderOptions() {
+	type tagged struct {
+		Name  Value[string] `custom:"n"`
+		Other Value[string] `custom:"o"`
+	}
+	av, err := MarshalMap(tagged{Name: New("Alice")}, func(o *attributevalue.EncoderOptions) {
+		o.TagKey = "custom"
+	})
+	s.Require().NoError(err)
+	s.Contains(av, "n")
+	s.NotContains(av, "o")
+}
+
+func (s *ItemSuite) TestMarshalMap_EncoderOptionsInsideValues() {
+	type custom struct {
+		X int `custom:"x"`
+	}
+	type tagged struct {
+		Inner Value[custom]            `custom:"i"`
+		List  []Value[custom]          `custom:"l"`
+		Map   map[string]Value[custom] `custom:"m"`
+	}
+	in := tagged{
+		Inner: New(custom{X: 1}),
+		List:  []Value[custom]{New(custom{X: 2}), NewNull[custom]()},
+		Map:   map[string]Value[custom]{"a": New(custom{X: 3}), "b": {}},
+	}
+	tagKey := func(o *attributevalue.EncoderOptions) { o.TagKey = "custom" }
+	av, err := MarshalMap(in, tagKey)
+	s.Require().NoError(err)
+
+	x := func(n string) types.AttributeValue {
+		return &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"x": &types.AttributeValueMemberN{Value: n},
+		}}
+	}
+	s.Equal(map[string]types.AttributeValue{
+		"i": x("1"),
+		"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			x("2"), &types.AttributeValueMemberNULL{Value: true},
+		}},
+		"m": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"a": x("3")}},
+	}, av)
+
+	var out tagged
+	s.Require().NoError(UnmarshalMap(av, &out, func(o *attributevalue.DecoderOptions) {
+		o.TagKey = "custom"
+	}))
+	s.Equal(in.Inner, out.Inner)
+	s.Equal(in.List, out.List)
+	s.Equal(map[string]Value[custom]{"a": New(custom{X: 3})}, out.Map)
+
+	// Without options, Values use the default tag key and field name.
+	av, err = MarshalMap(tagged{Inner: New(custom{X: 1})})
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"X": &types.AttributeValueMemberN{Value: "1"},
+	}}, av["Inner"])
+}
+
+func (s *ItemSuite) TestMarshalMap_PlainNullValues() {
+	type inner struct {
+		Zip null.Value[string] `dynamodbav:"zip"`
+	}
+	type plain struct {
+		Name  null.Value[string]   `dynamodbav:"name"`
+		Email null.Value[string]   `dynamodbav:"email"`
+		Phone null.Value[string]   `dynamodbav:"phone"`
+		Tags  null.Value[[]string] `dynamodbav:"tags"`
+		Home  null.Value[inner]    `dynamodbav:"home"`
+	}
+	av, err := MarshalMap(plain{
+		Name:  null.New("x"),
+		Email: null.NewNull[string](),
+		Tags:  null.New([]string{"a"}),
+		Home:  null.New(inner{Zip: null.NewNull[string]()}),
+	})
+	s.Require().NoError(err)
+	s.Equal(map[string]types.AttributeValue{
+		"name":  &types.AttributeValueMemberS{Value: "x"},
+		"email": &types.AttributeValueMemberNULL{Value: true},
+		"tags": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "a"},
+		}},
+		"home": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"zip": &types.AttributeValueMemberNULL{Value: true},
+		}},
+	}, av)
+}
+
+func (s *ItemSuite) TestMarshalMap_Sets() {
+	type sets struct {
+		Tags  Value[[]string]      `dynamodbav:"tags,stringset"`
+		Nums  Value[[]int]         `dynamodbav:"nums,numberset"`
+		Plain null.Value[[]string] `dynamodbav:"plain,stringset"`
+		Empty Value[[]string]      `dynamodbav:"empty,stringset"`
+		List  Value[[]string]      `dynamodbav:"list"`
+	}
+	in := sets{
+		Tags:  New([]string{"a", "b"}),
+		Nums:  New([]int{1, 2}),
+		Plain: null.New([]string{"c"}),
+		Empty: New([]string{}),
+		List:  New([]string{"d"}),
+	}
+	av, err := MarshalMap(in)
+	s.Require().NoError(err)
+	s.Equal(map[string]types.AttributeValue{
+		"tags":  &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"nums":  &types.AttributeValueMemberNS{Value: []string{"1", "2"}},
+		"plain": &types.AttributeValueMemberSS{Value: []string{"c"}},
+		"empty": &types.AttributeValueMemberNULL{Value: true},
+		"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "d"},
+		}},
+	}, av)
+
+	var out struct {
+		Tags  Value[[]string] `dynamodbav:"tags,stringset"`
+		Nums  Value[[]int]    `dynamodbav:"nums,numberset"`
+		Empty Value[[]string] `dynamodbav:"empty,stringset"`
+	}
+	s.Require().NoError(UnmarshalMap(av, &out))
+	s.Equal(in.Tags, out.Tags)
+	s.Equal(in.Nums, out.Nums)
+	s.True(out.Empty.IsNull())
+}
+
+func (s *ItemSuite) TestMarshalMap_NotAStruct() {
+	_, err := MarshalMap("item")
+	s.Error(err)
+}
+
+func (s *ItemSuite) TestAttributeValueMarshalMap_KeepsUnsetAsNull() {
+	av, err := attributevalue.MarshalMap(item{})
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberNULL{Value: true}, av["gsi1pk"])
+}
+
+func (s *ItemSuite) TestRoundTrip_PreservesThreeStates() {
+	original := item{
+		PK:      "user#1",
+		Name:    New("Alice"),
+		Email:   NewNull[string](),
+		Address: itemAddress{Zip: NewNull[string]()},
+	}
+	av, err := MarshalMap(original)
+	s.Require().NoError(err)
+
+	var decoded item
+	s.Require().NoError(UnmarshalMap(av, &decoded))
+	s.Equal(original, decoded)
+	s.False(decoded.GSI.IsSet())
+	s.False(decoded.Address.City.IsSet())
+	s.True(decoded.Address.Zip.IsNull())
+}
+
+func (s *ItemSuite) TestUnmarshalMap_ResetsAbsentAttributes() {
+	decoded := item{Name: New("stale"), GSI: New("stale")}
+	err := UnmarshalMap(map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "user#1"},
+	}, &decoded)
+	s.Require().NoError(err)
+	s.Equal("user#1", decoded.PK)
+	s.False(decoded.Name.IsSet())
+	s.False(decoded.GSI.IsSet())
+}
+
+func (s *ItemSuite) TestUnmarshalMap_RequiresPointer() {
+	s.Error(UnmarshalMap(nil, item{}))
+}
@@ -0,0 +1,159 @@
+package nullddb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+)
+
+// ErrNoAttributes is returned by BuildUpdate when every field of the patch
+// is Unset, so there is nothing to update.
+var ErrNoAttributes = errors.New("nullddb: no attributes set")
+
+// Update holds the expression parts of a dynamodb.UpdateItemInput, named
+// after the fields they belong in. ExpressionAttributeValues is nil when
+// the expression has no values, as DynamoDB rejects an empty map.
+type Update struct {
+	UpdateExpression          string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues map[string]types.AttributeValue
+}
+
+// BuildUpdate builds an UpdateExpression from a patch struct of Value (or
+// null.Value) fields:
+//   - Unset fields are skipped.
+//   - Null fields are removed with REMOVE, or set to a NULL attribute when
+//     their tag has the setnull option, e.g. `dynamodbav:"nickname,setnull"`.
+//   - Valid fields are set with SET.
+//
+// Attribute names come from `dynamodbav` tags, falling back to the field
+// name; untagged embedded structs are flattened and `dynamodbav:"-"` skips
+// a field. Fields that are not Values, such as key attributes, are ignored.
+//
+// It returns ErrNoAttributes when every Value field is Unset.
+func BuildUpdate(patch any) (Update, error) {
+	rv := reflect.ValueOf(patch)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return Update{}, ErrNoAttributes
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return Update{}, fmt.Errorf("nullddb: BuildUpdate expects a struct, got %T", patch)
+	}
+
+	var set, remove []string
+	u := Update{ExpressionAttributeNames: make(map[string]string)}
+	values := make(map[string]types.AttributeValue)
+
+	for _, f := range updateFields(rv.Type()) {
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+
+		var av types.AttributeValue
+		switch fv.Interface().(interface{ State() null.State }).State() {
+		case null.Unset:
+			continue
+		case null.Null:
+			if !f.setNull {
+				name := "#a" + strconv.Itoa(len(u.ExpressionAttributeNames))
+				u.ExpressionAttributeNames[name] = f.name
+				remove = append(remove, name)
+				continue
+			}
+			av = &types.AttributeValueMemberNULL{Value: true}
+		default:
+			if av, err = marshalValid(fv, f.set); err != nil {
+				return Update{}, fmt.Errorf("nullddb: attribute %s: %w", f.name, err)
+			}
+		}
+
+		name := "#a" + strconv.Itoa(len(u.ExpressionAttributeNames))
+		value := ":v" + strconv.Itoa(len(values))
+		u.ExpressionAttributeNames[name] = f.name
+		values[value] = av
+		set = append(set, name+" = "+value)
+	}
+
+	if len(set) == 0 && len(remove) == 0 {
+		return Update{}, ErrNoAttributes
+	}
+
+	var clauses []string
+	if len(set) > 0 {
+		clauses = append(clauses, "SET "+strings.Join(set, ", "))
+	}
+	if len(remove) > 0 {
+		clauses = append(clauses, "REMOVE "+strings.Join(remove, ", "))
+	}
+	u.UpdateExpression = strings.Join(clauses, " ")
+	if len(values) > 0 {
+		u.ExpressionAttributeValues = values
+	}
+	return u, nil
+}
+
+// marshalValid marshals a Valid Value field. nullddb Values marshal
+// themselves; plain null.Values are marshaled through their inner value.
+// Unset Values nested inside are omitted and set is honored, as with
+// MarshalMap.
+func marshalValid(fv reflect.Value, set string) (types.AttributeValue, error) {
+	e := &itemEncoder{enc: attributevalue.NewEncoder(), tagKey: defaultTagKey}
+	av, err := e.enc.Encode(fv.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return e.fix(av, fv, set)
+}
+
+type updateField struct {
+	name    string
+	index   []int
+	setNull bool
+	set     string // stringset, numberset or binaryset
+}
+
+// updateFields lists the Value fields of struct type t.
+func updateFields(t reflect.Type) []updateField {
+	var fields []updateField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("dynamodbav")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := sf.Type
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct && !bridge.IsValue(ft) {
+			for _, f := range updateFields(ft) {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if !sf.IsExported() || !bridge.IsValue(ft) {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		f := updateField{name: name, index: []int{i}, set: setOption(opts)}
+		for opt := range strings.SplitSeq(opts, ",") {
+			if opt == "setnull" {
+				f.setNull = true
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
@@ -0,0 +1,131 @@
+package nullddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/suite"
+)
+
+type Audit struct {
+	UpdatedBy Value[string] `dynamodbav:"updated_by"`
+}
+
+type userPatch struct {
+	PK       string             `dynamodbav:"pk"`
+	Name     Value[string]      `dynamodbav:"name"`
+	Email    null.Value[string] `dynamodbav:"email"`
+	Nickname Value[string]      `dynamodbav:"nickname,setnull"`
+	Age      Value[int]
+	Ignored  Value[string] `dynamodbav:"-"`
+	Audit
+}
+
+type UpdateSuite struct {
+	suite.Suite
+}
+
+func TestUpdateSuite(t *testing.T) {
+	suite.Run(t, new(UpdateSuite))
+}
+
+func (s *UpdateSuite) TestSetAndRemove() {
+	u, err := BuildUpdate(userPatch{
+		PK:    "user#1",
+		Name:  New("Alice"),
+		Email: null.NewNull[string](),
+		Age:   New(30),
+	})
+	s.Require().NoError(err)
+	s.Equal("SET #a0 = :v0, #a2 = :v1 REMOVE #a1", u.UpdateExpression)
+	s.Equal(map[string]string{"#a0": "name", "#a1": "email", "#a2": "Age"}, u.ExpressionAttributeNames)
+	s.Equal(map[string]types.AttributeValue{
+		":v0": &types.AttributeValueMemberS{Value: "Alice"},
+		":v1": &types.AttributeValueMemberN{Value: "30"},
+	}, u.ExpressionAttributeValues)
+}
+
+func (s *UpdateSuite) TestSetNull() {
+	u, err := BuildUpdate(&userPatch{Nickname: NewNull[string]()})
+	s.Require().NoError(err)
+	s.Equal("SET #a0 = :v0", u.UpdateExpression)
+	s.Equal(map[string]string{"#a0": "nickname"}, u.ExpressionAttributeNames)
+	s.Equal(map[string]types.AttributeValue{
+		":v0": &types.AttributeValueMemberNULL{Value: true},
+	}, u.ExpressionAttributeValues)
+}
+
+func (s *UpdateSuite) TestRemoveOnly() {
+	u, err := BuildUpdate(userPatch{Name: NewNull[string](), Audit: Audit{UpdatedBy: NewNull[string]()}})
+	s.Require().NoError(err)
+	s.Equal("REMOVE #a0, #a1", u.UpdateExpression)
+	s.Equal(map[string]string{"#a0": "name", "#a1": "updated_by"}, u.ExpressionAttributeNames)
+	s.Nil(u.ExpressionAttributeValues)
+}
+
+func (s *UpdateSuite) TestPlainNullValue() {
+	type patch struct {
+		Tags null.Value[[]string] `dynamodbav:"tags"`
+	}
+	u, err := BuildUpdate(patch{Tags: null.New([]string{"a"})})
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "a"},
+	}}, u.ExpressionAttributeValues[":v0"])
+
+	type named struct {
+		Name null.Value[string] `dynamodbav:"name"`
+	}
+	u, err = BuildUpdate(struct {
+		Names null.Value[[]named] `dynamodbav:"names"`
+	}{Names: null.New([]named{{Name: null.New("a")}, {Name: null.NewNull[string]()}})})
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"name": &types.AttributeValueMemberS{Value: "a"}}},
+		&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"name": &types.AttributeValueMemberNULL{Value: true}}},
+	}}, u.ExpressionAttributeValues[":v0"])
+}
+
+func (s *UpdateSuite) TestSetOption() {
+	type patch struct {
+		Tags Value[[]string] `dynamodbav:"tags,stringset"`
+	}
+	u, err := BuildUpdate(patch{Tags: New([]string{"a", "b"})})
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberSS{Value: []string{"a", "b"}}, u.ExpressionAttributeValues[":v0"])
+}
+
+func (s *UpdateSuite) TestNestedUnsetOmitted() {
+	type address struct {
+		City Value[string] `dynamodbav:"city"`
+		Zip  Value[string] `dynamodbav:"zip"`
+	}
+	type patch struct {
+		Home Value[address] `dynamodbav:"home"`
+	}
+	u, err := BuildUpdate(patch{Home: New(address{City: New("Paris")})})
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"city": &types.AttributeValueMemberS{Value: "Paris"},
+	}}, u.ExpressionAttributeValues[":v0"])
+}
+
+func (s *UpdateSuite) TestNothingSet() {
+	_, err := BuildUpdate(userPatch{PK: "user#1", Ignored: New("x")})
+	s.ErrorIs(err, ErrNoAttributes)
+
+	_, err = BuildUpdate((*userPatch)(nil))
+	s.ErrorIs(err, ErrNoAttributes)
+}
+
+func (s *UpdateSuite) TestErrors() {
+	_, err := BuildUpdate("patch")
+	s.Error(err)
+
+	type patch struct {
+		Bad Value[chan int] `dynamodbav:"bad"`
+	}
+	_, err = BuildUpdate(patch{Bad: New(make(chan int))})
+	s.Error(err)
+}
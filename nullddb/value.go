@@ -5,11 +5,24 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/bjaus/null"
 )
 
 // Value wraps null.Value[T] and adds DynamoDB marshaling support.
+//
+// Strings, numbers, bools, []byte and time.Time are converted directly.
+// Any other T (structs, maps, slices, or types implementing
+// attributevalue.Marshaler and attributevalue.Unmarshaler) goes through the
+// attributevalue encoder and decoder, so Value[Address] round-trips as an M
+// attribute and Value[[]string] as an L (and decodes from an SS).
+// MarshalMap and BuildUpdate honor a field's stringset, numberset or
+// binaryset option, which the encoder does not pass to Values.
+//
+// attributevalue.Marshaler does not receive the options of the encoder that
+// calls it, so those types are converted with the default options. Pass
+// options to MarshalMap and UnmarshalMap to have them apply inside Values.
 type Value[T any] struct {
 	null.Value[T]
 }
@@ -38,52 +51,73 @@ func From[T any](v null.Value[T]) Value[T] {
 
 // --- DynamoDB Marshaler ---
 
+// MarshalDynamoDBAttributeValue implements attributevalue.Marshaler.
+// Null and Unset values are both marshaled as NULL; use MarshalMap to omit
+// Unset values instead.
 func (v Value[T]) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
 	if !v.IsValid() {
 		return &types.AttributeValueMemberNULL{Value: true}, nil
 	}
 
 	val := any(v.Get())
+	if av, ok := marshalBuiltin(val); ok {
+		return av, nil
+	}
+	av, err := attributevalue.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	if av == nil {
+		return nil, fmt.Errorf("nullddb: unsupported type %T", val)
+	}
+	return av, nil
+}
+
+// marshalBuiltin converts the types of builtinTypes, reporting false for
+// any other.
+func marshalBuiltin(val any) (types.AttributeValue, bool) {
 	switch x := val.(type) {
 	case string:
-		return &types.AttributeValueMemberS{Value: x}, nil
+		return &types.AttributeValueMemberS{Value: x}, true
 	case int:
-		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(x), 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(x), 10)}, true
 	case int64:
-		return &types.AttributeValueMemberN{Value: strconv.FormatInt(x, 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(x, 10)}, true
 	case int32:
-		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(x), 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(x), 10)}, true
 	case int16:
-		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(x), 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(x), 10)}, true
 	case int8:
-		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(x), 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(x), 10)}, true
 	case uint:
-		return &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(x), 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(x), 10)}, true
 	case uint64:
-		return &types.AttributeValueMemberN{Value: strconv.FormatUint(x, 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatUint(x, 10)}, true
 	case uint32:
-		return &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(x), 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(x), 10)}, true
 	case uint16:
-		return &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(x), 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(x), 10)}, true
 	case uint8:
-		return &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(x), 10)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatUint(uint64(x), 10)}, true
 	case float64:
-		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(x, 'f', -1, 64)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(x, 'f', -1, 64)}, true
 	case float32:
-		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(x), 'f', -1, 32)}, nil
+		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(x), 'f', -1, 32)}, true
 	case bool:
-		return &types.AttributeValueMemberBOOL{Value: x}, nil
+		return &types.AttributeValueMemberBOOL{Value: x}, true
 	case []byte:
-		return &types.AttributeValueMemberB{Value: x}, nil
+		return &types.AttributeValueMemberB{Value: x}, true
 	case time.Time:
-		return &types.AttributeValueMemberS{Value: x.Format(time.RFC3339Nano)}, nil
-	default:
-		return nil, fmt.Errorf("nullddb: unsupported type %T", val)
+		return &types.AttributeValueMemberS{Value: x.Format(time.RFC3339Nano)}, true
 	}
+	return nil, false
 }
 
 // --- DynamoDB Unmarshaler ---
 
+// UnmarshalDynamoDBAttributeValue implements attributevalue.Unmarshaler.
+// A NULL attribute makes the Value Null; anything else makes it Valid.
+// Absent attributes are never decoded, so they stay Unset.
 func (v *Value[T]) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
 	if _, ok := av.(*types.AttributeValueMemberNULL); ok {
 		*v = NewNull[T]()
@@ -177,7 +211,9 @@ func (v *Value[T]) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) erro
 			return err
 		}
 	default:
-		return fmt.Errorf("nullddb: unsupported type %T", ptr)
+		if err := attributevalue.Unmarshal(av, ptr); err != nil {
+			return err
+		}
 	}
 
 	*v = New(*target.(*T))
@@ -1,6 +1,8 @@
 package nullddb
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -182,9 +184,35 @@ func (s *MarshalSuite) TestMarshal_Time() {
 	s.True(parsed.Equal(now))
 }
 
-func (s *MarshalSuite) TestMarshal_UnsupportedType() {
+func (s *MarshalSuite) TestMarshal_Struct() {
 	type Custom struct{ X int }
 	v := New(Custom{X: 42})
+	av, err := v.MarshalDynamoDBAttributeValue()
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"X": &types.AttributeValueMemberN{Value: "42"},
+	}}, av)
+}
+
+func (s *MarshalSuite) TestMarshal_Slice() {
+	v := New([]string{"a", "b"})
+	av, err := v.MarshalDynamoDBAttributeValue()
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "a"},
+		&types.AttributeValueMemberS{Value: "b"},
+	}}, av)
+}
+
+func (s *MarshalSuite) TestMarshal_Marshaler() {
+	v := New(upper("hello"))
+	av, err := v.MarshalDynamoDBAttributeValue()
+	s.Require().NoError(err)
+	s.Equal(&types.AttributeValueMemberS{Value: "HELLO"}, av)
+}
+
+func (s *MarshalSuite) TestMarshal_UnsupportedType() {
+	v := New(make(chan int))
 	_, err := v.MarshalDynamoDBAttributeValue()
 	s.Error(err)
 }
@@ -363,13 +391,61 @@ func (s *UnmarshalSuite) TestUnmarshal_Time_Errors() {
 	s.Error(v2.UnmarshalDynamoDBAttributeValue(avbad))
 }
 
-func (s *UnmarshalSuite) TestUnmarshal_UnsupportedType() {
+func (s *UnmarshalSuite) TestUnmarshal_Struct() {
+	type Custom struct{ X int }
+	av := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"X": &types.AttributeValueMemberN{Value: "42"},
+	}}
+	var v Value[Custom]
+	s.Require().NoError(v.UnmarshalDynamoDBAttributeValue(av))
+	s.Equal(Custom{X: 42}, v.Get())
+}
+
+func (s *UnmarshalSuite) TestUnmarshal_StringSet() {
+	av := &types.AttributeValueMemberSS{Value: []string{"a", "b"}}
+	var v Value[[]string]
+	s.Require().NoError(v.UnmarshalDynamoDBAttributeValue(av))
+	s.Equal([]string{"a", "b"}, v.Get())
+}
+
+func (s *UnmarshalSuite) TestUnmarshal_Map() {
+	av := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"a": &types.AttributeValueMemberN{Value: "1"},
+	}}
+	var v Value[map[string]int]
+	s.Require().NoError(v.UnmarshalDynamoDBAttributeValue(av))
+	s.Equal(map[string]int{"a": 1}, v.Get())
+}
+
+func (s *UnmarshalSuite) TestUnmarshal_Unmarshaler() {
+	var v Value[upper]
+	s.Require().NoError(v.UnmarshalDynamoDBAttributeValue(&types.AttributeValueMemberS{Value: "HELLO"}))
+	s.Equal(upper("hello"), v.Get())
+}
+
+func (s *UnmarshalSuite) TestUnmarshal_FallbackError() {
 	type Custom struct{ X int }
 	av := &types.AttributeValueMemberS{Value: "hello"}
 	var v Value[Custom]
 	s.Error(v.UnmarshalDynamoDBAttributeValue(av))
 }
 
+// upper is stored upper-cased and read back lower-cased.
+type upper string
+
+func (u upper) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return &types.AttributeValueMemberS{Value: strings.ToUpper(string(u))}, nil
+}
+
+func (u *upper) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	s, ok := av.(*types.AttributeValueMemberS)
+	if !ok {
+		return fmt.Errorf("upper: unexpected %T", av)
+	}
+	*u = upper(strings.ToLower(s.Value))
+	return nil
+}
+
 // --- Integration Tests ---
 
 func TestIntegration_MarshalUnmarshal(t *testing.T) {
@@ -0,0 +1,209 @@
+// Package nullenv decodes environment variables into structs of null.Value
+// fields, keeping apart variables that are not defined (Unset), defined but
+// empty (Null) and defined with a value (Valid).
+//
+// Variable names come from `env` struct tags, or the upper snake case field
+// name when untagged (MaxConns reads MAX_CONNS); `env:"-"` skips a field.
+// Nested structs add their own name and an underscore to the prefix of
+// their fields, while untagged embedded structs are flattened:
+//
+//	type Config struct {
+//	    Port    null.Value[int]           `env:"PORT"`
+//	    Hosts   null.Value[[]string]      `env:"HOSTS"`   // HOSTS=a,b,c
+//	    Timeout null.Value[time.Duration] `env:"TIMEOUT"` // TIMEOUT=30s
+//	    DB      struct {
+//	        URL null.Value[string] `env:"URL"`            // DB_URL
+//	    } `env:"DB"`
+//	}
+//
+//	err := nullenv.Decode(&cfg, nullenv.WithPrefix("APP_"))
+//
+// Values are parsed like null.Value.UnmarshalText: with T's own
+// UnmarshalText if it has one, and with strconv for strings, bools, numbers
+// and time.Duration. Slices are split on a separator and parsed element by
+// element. Fields of any other type are ignored.
+package nullenv
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/snake"
+	"github.com/bjaus/null/internal/textconv"
+)
+
+// --- Options ---
+
+// Option configures Decode.
+type Option func(*decoder)
+
+// WithLookup sets the function used to read variables, os.LookupEnv by
+// default. It reports false for variables that are not defined.
+func WithLookup(lookup func(key string) (string, bool)) Option {
+	return func(d *decoder) { d.lookup = lookup }
+}
+
+// WithPrefix prepends prefix to every variable name, e.g. "APP_".
+func WithPrefix(prefix string) Option {
+	return func(d *decoder) { d.prefix = prefix }
+}
+
+// WithSeparator sets the separator between slice elements, "," by default.
+func WithSeparator(sep string) Option {
+	return func(d *decoder) { d.sep = sep }
+}
+
+// WithEmptyAsNull sets whether a variable that is defined but empty makes a
+// field Null (the default). When disabled, the empty string is parsed like
+// any other value, so it is a Valid empty string or slice and an error for
+// numbers.
+func WithEmptyAsNull(enabled bool) Option {
+	return func(d *decoder) { d.emptyNull = enabled }
+}
+
+// --- Decoding ---
+
+type decoder struct {
+	lookup    func(string) (string, bool)
+	prefix    string
+	sep       string
+	emptyNull bool
+}
+
+// Decode populates the null.Value fields of the struct dst points to from
+// the environment. Fields whose variable is not defined are left untouched,
+// so dst may carry defaults. A nil pointer to a nested struct is only
+// allocated when one of its variables is defined.
+func Decode(dst any, opts ...Option) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("nullenv: Decode requires a non-nil pointer to a struct, got %T", dst)
+	}
+	d := &decoder{lookup: os.LookupEnv, sep: ",", emptyNull: true}
+	for _, opt := range opts {
+		opt(d)
+	}
+	_, err := d.decodeStruct(rv.Elem(), d.prefix)
+	return err
+}
+
+// decodeStruct decodes the fields of v and reports whether any of their
+// variables was defined.
+func (d *decoder) decodeStruct(v reflect.Value, prefix string) (bool, error) {
+	found := false
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("env")
+		if tag == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		fv := v.Field(i)
+
+		if bridge.IsValue(sf.Type) {
+			if !sf.IsExported() {
+				continue
+			}
+			name := tag
+			if name == "" {
+				name = snake.Upper(sf.Name)
+			}
+			ok, err := d.decodeValue(fv, prefix+name)
+			if err != nil {
+				return found, err
+			}
+			found = found || ok
+			continue
+		}
+
+		st := sf.Type
+		if st.Kind() == reflect.Pointer {
+			st = st.Elem()
+		}
+		if st.Kind() != reflect.Struct || textconv.IsText(st) {
+			continue
+		}
+		nested := prefix
+		if tag != "" {
+			nested += tag + "_"
+		} else if !sf.Anonymous {
+			nested += snake.Upper(sf.Name) + "_"
+		}
+
+		ok, err := d.decodeNested(fv, nested)
+		if err != nil {
+			return found, err
+		}
+		found = found || ok
+	}
+	return found, nil
+}
+
+func (d *decoder) decodeNested(fv reflect.Value, prefix string) (bool, error) {
+	if fv.Kind() != reflect.Pointer {
+		return d.decodeStruct(fv, prefix)
+	}
+	if !fv.IsNil() {
+		return d.decodeStruct(fv.Elem(), prefix)
+	}
+	if !fv.CanSet() {
+		return false, nil
+	}
+	target := reflect.New(fv.Type().Elem())
+	ok, err := d.decodeStruct(target.Elem(), prefix)
+	if ok {
+		fv.Set(target)
+	}
+	return ok, err
+}
+
+// decodeValue sets the null.Value fv from variable key and reports whether
+// the variable was defined.
+func (d *decoder) decodeValue(fv reflect.Value, key string) (bool, error) {
+	s, ok := d.lookup(key)
+	if !ok {
+		return false, nil
+	}
+	if s == "" && d.emptyNull {
+		bridge.Set(fv, reflect.Value{})
+		return true, nil
+	}
+
+	elem := bridge.Elem(fv.Type())
+	if elem.Kind() == reflect.Slice && !textconv.IsText(elem) {
+		if s == null.NullText {
+			bridge.Set(fv, reflect.Value{})
+			return true, nil
+		}
+		x, err := d.parseSlice(elem, s)
+		if err != nil {
+			return true, fmt.Errorf("nullenv: variable %s: %w", key, err)
+		}
+		bridge.Set(fv, x)
+		return true, nil
+	}
+
+	if err := fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+		return true, fmt.Errorf("nullenv: variable %s: %w", key, err)
+	}
+	return true, nil
+}
+
+func (d *decoder) parseSlice(t reflect.Type, s string) (reflect.Value, error) {
+	if s == "" {
+		return reflect.MakeSlice(t, 0, 0), nil
+	}
+	parts := strings.Split(s, d.sep)
+	x := reflect.MakeSlice(t, len(parts), len(parts))
+	for i, p := range parts {
+		if err := textconv.Unmarshal(x.Index(i), []byte(strings.TrimSpace(p))); err != nil {
+			return x, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return x, nil
+}
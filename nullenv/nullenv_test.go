@@ -0,0 +1,192 @@
+package nullenv
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/snake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type dbConfig struct {
+	URL      null.Value[string] `env:"URL"`
+	MaxConns null.Value[int]
+}
+
+type Common struct {
+	Debug null.Value[bool] `env:"DEBUG"`
+}
+
+type config struct {
+	Port    null.Value[int]           `env:"PORT"`
+	Host    null.Value[string]        `env:"HOST"`
+	Hosts   null.Value[[]string]      `env:"HOSTS"`
+	Ports   null.Value[[]int]         `env:"PORTS"`
+	Timeout null.Value[time.Duration] `env:"TIMEOUT"`
+	Addr    null.Value[netip.Addr]    `env:"ADDR"`
+	Skipped null.Value[string]        `env:"-"`
+	Plain   string                    `env:"PLAIN"`
+	DB      dbConfig                  `env:"DB"`
+	Cache   *dbConfig
+	Common
+}
+
+func lookup(env map[string]string) Option {
+	return WithLookup(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+}
+
+type DecodeSuite struct {
+	suite.Suite
+}
+
+func TestDecodeSuite(t *testing.T) {
+	suite.Run(t, new(DecodeSuite))
+}
+
+func (s *DecodeSuite) TestThreeStates() {
+	var cfg config
+	err := Decode(&cfg, lookup(map[string]string{
+		"PORT": "8080",
+		"HOST": "",
+	}))
+	s.Require().NoError(err)
+	s.Equal(null.New(8080), cfg.Port)
+	s.True(cfg.Host.IsNull())
+	s.False(cfg.Timeout.IsSet())
+}
+
+func (s *DecodeSuite) TestTypes() {
+	var cfg config
+	err := Decode(&cfg, lookup(map[string]string{
+		"HOSTS":   "a, b,c",
+		"PORTS":   "80,443",
+		"TIMEOUT": "1m30s",
+		"ADDR":    "10.0.0.1",
+		"DEBUG":   "true",
+		"PLAIN":   "ignored",
+		"SKIPPED": "ignored",
+	}))
+	s.Require().NoError(err)
+	s.Equal(null.New([]string{"a", "b", "c"}), cfg.Hosts)
+	s.Equal(null.New([]int{80, 443}), cfg.Ports)
+	s.Equal(null.New(90*time.Second), cfg.Timeout)
+	s.Equal(null.New(netip.MustParseAddr("10.0.0.1")), cfg.Addr)
+	s.Equal(null.New(true), cfg.Debug)
+	s.Empty(cfg.Plain)
+	s.False(cfg.Skipped.IsSet())
+}
+
+func (s *DecodeSuite) TestNested() {
+	var cfg config
+	err := Decode(&cfg, lookup(map[string]string{
+		"DB_URL":       "postgres://",
+		"DB_MAX_CONNS": "10",
+	}))
+	s.Require().NoError(err)
+	s.Equal(null.New("postgres://"), cfg.DB.URL)
+	s.Equal(null.New(10), cfg.DB.MaxConns)
+	s.Nil(cfg.Cache)
+}
+
+func (s *DecodeSuite) TestNestedPointer() {
+	var cfg config
+	err := Decode(&cfg, lookup(map[string]string{"CACHE_URL": "redis://"}))
+	s.Require().NoError(err)
+	s.Require().NotNil(cfg.Cache)
+	s.Equal(null.New("redis://"), cfg.Cache.URL)
+}
+
+func (s *DecodeSuite) TestPrefix() {
+	var cfg config
+	err := Decode(&cfg, WithPrefix("APP_"), lookup(map[string]string{
+		"APP_PORT":   "1",
+		"APP_DB_URL": "x",
+		"APP_DEBUG":  "false",
+		"PORT":       "2",
+	}))
+	s.Require().NoError(err)
+	s.Equal(null.New(1), cfg.Port)
+	s.Equal(null.New("x"), cfg.DB.URL)
+	s.Equal(null.New(false), cfg.Debug)
+}
+
+func (s *DecodeSuite) TestSeparator() {
+	var cfg config
+	err := Decode(&cfg, WithSeparator(";"), lookup(map[string]string{"HOSTS": "a,b;c"}))
+	s.Require().NoError(err)
+	s.Equal(null.New([]string{"a,b", "c"}), cfg.Hosts)
+}
+
+func (s *DecodeSuite) TestEmptyAsValue() {
+	var cfg config
+	err := Decode(&cfg, WithEmptyAsNull(false), lookup(map[string]string{
+		"HOST":  "",
+		"HOSTS": "",
+	}))
+	s.Require().NoError(err)
+	s.Equal(null.New(""), cfg.Host)
+	s.Equal(null.New([]string{}), cfg.Hosts)
+
+	err = Decode(&cfg, WithEmptyAsNull(false), lookup(map[string]string{"PORT": ""}))
+	s.Error(err)
+}
+
+func (s *DecodeSuite) TestNullText() {
+	var cfg config
+	err := Decode(&cfg, WithEmptyAsNull(false), lookup(map[string]string{
+		"PORT":  "null",
+		"HOSTS": "null",
+	}))
+	s.Require().NoError(err)
+	s.True(cfg.Port.IsNull())
+	s.True(cfg.Hosts.IsNull())
+}
+
+func (s *DecodeSuite) TestKeepsDefaults() {
+	cfg := config{Port: null.New(80)}
+	s.Require().NoError(Decode(&cfg, lookup(nil)))
+	s.Equal(null.New(80), cfg.Port)
+}
+
+func (s *DecodeSuite) TestErrors() {
+	var cfg config
+	err := Decode(&cfg, lookup(map[string]string{"PORT": "eighty"}))
+	s.ErrorContains(err, "nullenv: variable PORT")
+
+	err = Decode(&cfg, lookup(map[string]string{"PORTS": "80,x"}))
+	s.ErrorContains(err, "element 1")
+
+	s.Error(Decode(cfg))
+	s.Error(Decode((*config)(nil)))
+}
+
+func (s *DecodeSuite) TestOSLookup() {
+	s.T().Setenv("NULLENV_TEST_PORT", "9090")
+	var cfg struct {
+		Port null.Value[int] `env:"NULLENV_TEST_PORT"`
+	}
+	s.Require().NoError(Decode(&cfg))
+	s.Equal(null.New(9090), cfg.Port)
+}
+
+func TestSnake(t *testing.T) {
+	tests := map[string]string{
+		"Port":     "PORT",
+		"MaxConns": "MAX_CONNS",
+		"DBHost":   "DB_HOST",
+		"URL":      "URL",
+		"HTTP2Off": "HTTP2_OFF",
+		"UserID":   "USER_ID",
+	}
+	for in, want := range tests {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, want, snake.Upper(in))
+		})
+	}
+}
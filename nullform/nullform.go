@@ -0,0 +1,298 @@
+// Package nullform decodes url.Values, such as query strings and form
+// bodies, into structs of null.Value fields and encodes them back.
+//
+// A key that is absent leaves its field Unset, a key that is present but
+// empty (?status=) or equal to the null sentinel (?status=null) makes it
+// Null, and anything else is parsed into a Valid value:
+//
+//	type ListFilter struct {
+//	    Status null.Value[string]   `query:"status"`
+//	    Tags   null.Value[[]string] `query:"tag"`  // ?tag=a&tag=b
+//	    Limit  null.Value[int]      `query:"limit"`
+//	}
+//
+//	var f ListFilter
+//	err := nullform.Decode(r.URL.Query(), &f)
+//
+// Keys come from `form` struct tags, then `query` tags, or the lowercased
+// field name when untagged; "-" skips a field. Nested structs prefix their
+// keys with their own key and a dot (address.city) while untagged embedded
+// structs are flattened. Values are parsed like null.Value.UnmarshalText
+// and repeated keys fill slices. Fields of any other type are ignored.
+package nullform
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/textconv"
+)
+
+// ErrAmbiguous is returned by Encode for a Valid value that would not decode
+// back as Valid: one that is empty or equal to the null sentinel, which
+// decodes as Null, or an empty slice, which writes no key and decodes as
+// Unset.
+var ErrAmbiguous = errors.New("nullform: value would not decode as valid")
+
+// --- Options ---
+
+// Option configures Decode and Encode.
+type Option func(*codec)
+
+// WithNull sets the sentinel value that means Null, "null" by default.
+// Encode writes Null fields as the sentinel when empty values are not Null.
+func WithNull(sentinel string) Option {
+	return func(c *codec) { c.null = sentinel }
+}
+
+// WithEmptyAsNull sets whether a key with an empty value makes a field Null
+// (the default). When disabled the empty string is parsed like any other
+// value, so only the sentinel means Null.
+func WithEmptyAsNull(enabled bool) Option {
+	return func(c *codec) { c.emptyNull = enabled }
+}
+
+type codec struct {
+	null      string
+	emptyNull bool
+}
+
+func newCodec(opts []Option) *codec {
+	c := &codec{null: null.NullText, emptyNull: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *codec) isNull(s string) bool {
+	return s == c.null || (s == "" && c.emptyNull)
+}
+
+// --- Decoding ---
+
+// Decode populates the null.Value fields of the struct dst points to from
+// values. Fields whose key is absent are left untouched; for a field that
+// is not a slice only the first value of its key is used.
+func Decode(values url.Values, dst any, opts ...Option) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("nullform: Decode requires a non-nil pointer to a struct, got %T", dst)
+	}
+	c := newCodec(opts)
+	_, err := c.decodeStruct(values, rv.Elem(), "")
+	return err
+}
+
+// decodeStruct decodes the fields of v and reports whether any of their
+// keys was present.
+func (c *codec) decodeStruct(values url.Values, v reflect.Value, prefix string) (bool, error) {
+	found := false
+	for _, f := range fieldsOf(v.Type()) {
+		fv := v.Field(f.index)
+		key := prefix + f.name
+
+		var ok bool
+		var err error
+		switch {
+		case f.value:
+			ok, err = c.decodeValue(values, fv, key)
+		case fv.Kind() != reflect.Pointer:
+			ok, err = c.decodeStruct(values, fv, nestedPrefix(key, f))
+		case !fv.IsNil():
+			ok, err = c.decodeStruct(values, fv.Elem(), nestedPrefix(key, f))
+		case fv.CanSet():
+			target := reflect.New(fv.Type().Elem())
+			ok, err = c.decodeStruct(values, target.Elem(), nestedPrefix(key, f))
+			if ok {
+				fv.Set(target)
+			}
+		}
+		if err != nil {
+			return found, err
+		}
+		found = found || ok
+	}
+	return found, nil
+}
+
+func (c *codec) decodeValue(values url.Values, fv reflect.Value, key string) (bool, error) {
+	vs, ok := values[key]
+	if !ok {
+		return false, nil
+	}
+	if len(vs) == 0 || (len(vs) == 1 && c.isNull(vs[0])) {
+		bridge.Set(fv, reflect.Value{})
+		return true, nil
+	}
+
+	elem := bridge.Elem(fv.Type())
+	if elem.Kind() == reflect.Slice && !textconv.IsText(elem) {
+		x := reflect.MakeSlice(elem, len(vs), len(vs))
+		for i, s := range vs {
+			if err := textconv.Unmarshal(x.Index(i), []byte(s)); err != nil {
+				return true, fmt.Errorf("nullform: key %s: element %d: %w", key, i, err)
+			}
+		}
+		bridge.Set(fv, x)
+		return true, nil
+	}
+
+	x := reflect.New(elem).Elem()
+	if err := textconv.Unmarshal(x, []byte(vs[0])); err != nil {
+		return true, fmt.Errorf("nullform: key %s: %w", key, err)
+	}
+	bridge.Set(fv, x)
+	return true, nil
+}
+
+// --- Encoding ---
+
+// Encode returns the set null.Value fields of the struct (or pointer to
+// struct) src as url.Values. Unset fields are omitted, Null fields are
+// written as an empty value (or as the sentinel when empty values are not
+// Null) and slices are written as repeated keys.
+//
+// Encode fails with ErrAmbiguous rather than write a Valid value that
+// Decode would read back as Null or Unset: an empty value (unless
+// WithEmptyAsNull(false) is used), the sentinel, an empty slice or a slice
+// whose only element is one of those.
+func Encode(src any, opts ...Option) (url.Values, error) {
+	rv := reflect.ValueOf(src)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("nullform: Encode requires a struct, got %T", src)
+	}
+	c := newCodec(opts)
+	values := make(url.Values)
+	if err := c.encodeStruct(values, rv, ""); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (c *codec) encodeStruct(values url.Values, v reflect.Value, prefix string) error {
+	for _, f := range fieldsOf(v.Type()) {
+		fv := v.Field(f.index)
+		key := prefix + f.name
+
+		if !f.value {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if err := c.encodeStruct(values, fv, nestedPrefix(key, f)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch fv.Interface().(interface{ State() null.State }).State() {
+		case null.Unset:
+			continue
+		case null.Null:
+			if c.emptyNull {
+				values.Set(key, "")
+			} else {
+				values.Set(key, c.null)
+			}
+			continue
+		}
+
+		x := fv.MethodByName("Get").Call(nil)[0]
+		if x.Kind() == reflect.Slice && !textconv.IsText(x.Type()) {
+			if x.Len() == 0 {
+				return fmt.Errorf("%w: key %s: empty slice", ErrAmbiguous, key)
+			}
+			for i := range x.Len() {
+				b, err := textconv.Marshal(x.Index(i))
+				if err != nil {
+					return fmt.Errorf("nullform: key %s: element %d: %w", key, i, err)
+				}
+				values.Add(key, string(b))
+			}
+			if vs := values[key]; len(vs) == 1 && c.isNull(vs[0]) {
+				return fmt.Errorf("%w: key %s: element %q", ErrAmbiguous, key, vs[0])
+			}
+			continue
+		}
+		b, err := textconv.Marshal(x)
+		if err != nil {
+			return fmt.Errorf("nullform: key %s: %w", key, err)
+		}
+		if c.isNull(string(b)) {
+			return fmt.Errorf("%w: key %s: value %q", ErrAmbiguous, key, b)
+		}
+		values.Set(key, string(b))
+	}
+	return nil
+}
+
+// --- Fields ---
+
+type field struct {
+	name     string
+	index    int
+	value    bool // field is a null.Value
+	embedded bool // untagged embedded struct, flattened
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// fieldsOf lists the Value and nested struct fields of struct type t.
+func fieldsOf(t reflect.Type) []field {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.([]field)
+	}
+	var fields []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		name, ok := sf.Tag.Lookup("form")
+		if !ok {
+			name = sf.Tag.Get("query")
+		}
+		name, _, _ = strings.Cut(name, ",")
+		if name == "-" {
+			continue
+		}
+
+		f := field{name: name, index: i, value: bridge.IsValue(sf.Type)}
+		if f.value {
+			if !sf.IsExported() {
+				continue
+			}
+		} else {
+			st := sf.Type
+			if st.Kind() == reflect.Pointer {
+				st = st.Elem()
+			}
+			if st.Kind() != reflect.Struct || textconv.IsText(st) || (!sf.IsExported() && !sf.Anonymous) {
+				continue
+			}
+			f.embedded = sf.Anonymous && name == ""
+		}
+		if f.name == "" && !f.embedded {
+			f.name = strings.ToLower(sf.Name)
+		}
+		fields = append(fields, f)
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func nestedPrefix(key string, f field) string {
+	if f.embedded {
+		return key
+	}
+	return key + "."
+}
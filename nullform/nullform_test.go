@@ -0,0 +1,214 @@
+package nullform
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/suite"
+)
+
+type address struct {
+	City null.Value[string] `form:"city"`
+	Zip  null.Value[string]
+}
+
+type Paging struct {
+	Limit  null.Value[int] `query:"limit"`
+	Offset null.Value[int] `query:"offset"`
+}
+
+type listFilter struct {
+	Status  null.Value[string]    `query:"status"`
+	Tags    null.Value[[]string]  `query:"tag"`
+	IDs     null.Value[[]int]     `form:"id" query:"ignored"`
+	Since   null.Value[time.Time] `query:"since"`
+	Active  null.Value[bool]
+	Skipped null.Value[string] `form:"-"`
+	Plain   string             `form:"plain"`
+	Address address            `form:"address"`
+	Billing *address
+	Paging
+}
+
+type FormSuite struct {
+	suite.Suite
+}
+
+func TestFormSuite(t *testing.T) {
+	suite.Run(t, new(FormSuite))
+}
+
+func (s *FormSuite) decode(query string, opts ...Option) listFilter {
+	values, err := url.ParseQuery(query)
+	s.Require().NoError(err)
+	var f listFilter
+	s.Require().NoError(Decode(values, &f, opts...))
+	return f
+}
+
+func (s *FormSuite) TestThreeStates() {
+	f := s.decode("status=&limit=10")
+	s.True(f.Status.IsNull())
+	s.Equal(null.New(10), f.Limit)
+	s.False(f.Offset.IsSet())
+	s.False(f.Tags.IsSet())
+
+	f = s.decode("status=active&tag=null")
+	s.Equal(null.New("active"), f.Status)
+	s.True(f.Tags.IsNull())
+}
+
+func (s *FormSuite) TestTypes() {
+	f := s.decode("tag=a&tag=b&id=1&id=2&since=2024-01-15T10:00:00Z&active=true&plain=x&skipped=x&ignored=3")
+	s.Equal(null.New([]string{"a", "b"}), f.Tags)
+	s.Equal(null.New([]int{1, 2}), f.IDs)
+	s.Equal(null.New(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)), f.Since)
+	s.Equal(null.New(true), f.Active)
+	s.Empty(f.Plain)
+	s.False(f.Skipped.IsSet())
+}
+
+func (s *FormSuite) TestFirstValueForScalars() {
+	f := s.decode("status=a&status=b")
+	s.Equal(null.New("a"), f.Status)
+}
+
+func (s *FormSuite) TestNested() {
+	f := s.decode("address.city=Paris&address.zip=&billing.city=Lyon")
+	s.Equal(null.New("Paris"), f.Address.City)
+	s.True(f.Address.Zip.IsNull())
+	s.Require().NotNil(f.Billing)
+	s.Equal(null.New("Lyon"), f.Billing.City)
+
+	f = s.decode("status=x")
+	s.Nil(f.Billing)
+}
+
+func (s *FormSuite) TestCustomSentinel() {
+	f := s.decode("status=none&tag=null", WithNull("none"))
+	s.True(f.Status.IsNull())
+	s.Equal(null.New([]string{"null"}), f.Tags)
+}
+
+func (s *FormSuite) TestEmptyAsValue() {
+	f := s.decode("status=&address.city=null", WithEmptyAsNull(false))
+	s.Equal(null.New(""), f.Status)
+	s.True(f.Address.City.IsNull())
+}
+
+func (s *FormSuite) TestDecodeErrors() {
+	var f listFilter
+	err := Decode(url.Values{"limit": {"ten"}}, &f)
+	s.ErrorContains(err, "nullform: key limit")
+
+	err = Decode(url.Values{"id": {"1", "x"}}, &f)
+	s.ErrorContains(err, "element 1")
+
+	s.Error(Decode(url.Values{}, f))
+	s.Error(Decode(url.Values{}, (*listFilter)(nil)))
+}
+
+func (s *FormSuite) TestEncode() {
+	values, err := Encode(listFilter{
+		Status:  null.NewNull[string](),
+		Tags:    null.New([]string{"a", "b"}),
+		Active:  null.New(false),
+		Address: address{City: null.New("Paris")},
+		Paging:  Paging{Limit: null.New(10)},
+	})
+	s.Require().NoError(err)
+	s.Equal(url.Values{
+		"status":       {""},
+		"tag":          {"a", "b"},
+		"active":       {"false"},
+		"address.city": {"Paris"},
+		"limit":        {"10"},
+	}, values)
+}
+
+func (s *FormSuite) TestEncode_Sentinel() {
+	values, err := Encode(&listFilter{Status: null.NewNull[string]()}, WithEmptyAsNull(false), WithNull("none"))
+	s.Require().NoError(err)
+	s.Equal(url.Values{"status": {"none"}}, values)
+}
+
+func (s *FormSuite) TestRoundTrip() {
+	in := listFilter{
+		Status:  null.NewNull[string](),
+		IDs:     null.New([]int{3, 1}),
+		Since:   null.New(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)),
+		Billing: &address{Zip: null.New("75001")},
+		Paging:  Paging{Offset: null.New(20)},
+	}
+	values, err := Encode(in)
+	s.Require().NoError(err)
+
+	var out listFilter
+	s.Require().NoError(Decode(values, &out))
+	s.Equal(in, out)
+}
+
+func (s *FormSuite) TestEncodeAmbiguous() {
+	type form struct {
+		S null.Value[string]   `form:"s"`
+		T null.Value[[]string] `form:"t"`
+	}
+	tests := map[string]struct {
+		in   form
+		opts []Option
+	}{
+		"empty string":         {form{S: null.New("")}, nil},
+		"sentinel":             {form{S: null.New("null")}, nil},
+		"custom sentinel":      {form{S: null.New("none")}, []Option{WithNull("none")}},
+		"sentinel, empty kept": {form{S: null.New("null")}, []Option{WithEmptyAsNull(false)}},
+		"empty slice":          {form{T: null.New([]string{})}, nil},
+		"empty element":        {form{T: null.New([]string{""})}, nil},
+		"sentinel element":     {form{T: null.New([]string{"null"})}, nil},
+	}
+	for name, tt := range tests {
+		s.Run(name, func() {
+			_, err := Encode(tt.in, tt.opts...)
+			s.ErrorIs(err, ErrAmbiguous)
+		})
+	}
+}
+
+func (s *FormSuite) TestRoundTrip_EdgeValues() {
+	type form struct {
+		S null.Value[string]   `form:"s"`
+		T null.Value[[]string] `form:"t"`
+		N null.Value[string]   `form:"n"`
+	}
+	tests := map[string]struct {
+		in   form
+		opts []Option
+	}{
+		"empty string, empty kept": {form{S: null.New(""), N: null.NewNull[string]()}, []Option{WithEmptyAsNull(false)}},
+		"sentinel as other word":   {form{N: null.New("null"), S: null.NewNull[string]()}, []Option{WithNull("none")}},
+		"slice with empty element": {form{T: null.New([]string{"", "a"})}, nil},
+		"slice with sentinel":      {form{T: null.New([]string{"null", "null"})}, nil},
+		"null slice":               {form{T: null.NewNull[[]string]()}, nil},
+	}
+	for name, tt := range tests {
+		s.Run(name, func() {
+			values, err := Encode(tt.in, tt.opts...)
+			s.Require().NoError(err)
+			var out form
+			s.Require().NoError(Decode(values, &out, tt.opts...))
+			s.Equal(tt.in, out)
+		})
+	}
+}
+
+func (s *FormSuite) TestEncodeErrors() {
+	_, err := Encode("x")
+	s.Error(err)
+
+	type bad struct {
+		M null.Value[map[string]int] `form:"m"`
+	}
+	_, err = Encode(bad{M: null.New(map[string]int{})})
+	s.ErrorContains(err, "nullform: key m")
+}
@@ -0,0 +1,615 @@
+package nullmsgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/codec"
+)
+
+var errEOF = errors.New("nullmsgpack: unexpected end of data")
+
+// Unmarshal decodes the single MessagePack object in data into the value v
+// points to. nil leaves values other than null.Value untouched, except that
+// pointers, maps, slices and interfaces are set to nil. Decoding into an
+// empty interface produces bool, int64 (or uint64 above math.MaxInt64),
+// float64, string, []byte, time.Time, []any and map[string]any (or
+// map[any]any when a key is not a string).
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("nullmsgpack: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+	d := &decoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.off != len(d.data) {
+		return errors.New("nullmsgpack: unexpected data after top-level object")
+	}
+	return nil
+}
+
+// kind is the family of a MessagePack format.
+type kind uint8
+
+const (
+	kindNil kind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindStr
+	kindBin
+	kindArray
+	kindMap
+	kindExt
+)
+
+var kindNames = [...]string{"nil", "bool", "integer", "unsigned integer", "float", "string", "binary", "array", "map", "extension"}
+
+func (k kind) String() string { return kindNames[k] }
+
+// head is the decoded header of an object. n holds the length of a string,
+// binary, array, map or extension, or the value of an unsigned integer.
+type head struct {
+	kind kind
+	n    uint64
+	i    int64
+	f    float64
+	b    bool
+	ext  byte
+}
+
+type decoder struct {
+	data  []byte
+	off   int
+	depth codec.Depth
+}
+
+func (d *decoder) enter() error {
+	if err := d.depth.Enter(); err != nil {
+		return fmt.Errorf("nullmsgpack: %w", err)
+	}
+	return nil
+}
+
+func (d *decoder) leave() { d.depth.Leave() }
+
+func (d *decoder) peek() (byte, error) {
+	if d.off >= len(d.data) {
+		return 0, errEOF
+	}
+	return d.data[d.off], nil
+}
+
+// read returns the next n bytes.
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errEOF
+	}
+	p := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return p, nil
+}
+
+// uint reads a big-endian unsigned integer of size bytes.
+func (d *decoder) uint(size int) (uint64, error) {
+	p, err := d.read(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(p[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(p)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(p)), nil
+	}
+	return binary.BigEndian.Uint64(p), nil
+}
+
+// head reads the format byte of the next object and the length or value
+// that follows it. The contents of strings, binaries, extensions, arrays
+// and maps are left to be read.
+func (d *decoder) head() (h head, err error) {
+	c, err := d.peek()
+	if err != nil {
+		return h, err
+	}
+	d.off++
+
+	switch {
+	case c <= 0x7f:
+		return head{kind: kindUint, n: uint64(c)}, nil
+	case c >= 0xe0:
+		return head{kind: kindInt, i: int64(int8(c))}, nil
+	case c&0xf0 == fixMap:
+		return head{kind: kindMap, n: uint64(c & 0x0f)}, nil
+	case c&0xf0 == fixArray:
+		return head{kind: kindArray, n: uint64(c & 0x0f)}, nil
+	case c&0xe0 == fixStr:
+		return head{kind: kindStr, n: uint64(c & 0x1f)}, nil
+	}
+
+	sized := func(k kind, size int) (head, error) {
+		n, err := d.uint(size)
+		return head{kind: k, n: n}, err
+	}
+	switch c {
+	case mpNil:
+		return head{kind: kindNil}, nil
+	case mpFalse, mpTrue:
+		return head{kind: kindBool, b: c == mpTrue}, nil
+	case mpBin8, mpBin16, mpBin32:
+		return sized(kindBin, 1<<(c-mpBin8))
+	case mpStr8, mpStr16, mpStr32:
+		return sized(kindStr, 1<<(c-mpStr8))
+	case mpArr16, mpArr32:
+		return sized(kindArray, 2<<(c-mpArr16))
+	case mpMap16, mpMap32:
+		return sized(kindMap, 2<<(c-mpMap16))
+	case mpUint8, mpUint16, mpUint32, mpUint64:
+		return sized(kindUint, 1<<(c-mpUint8))
+	case mpInt8, mpInt16, mpInt32, mpInt64:
+		size := 1 << (c - mpInt8)
+		u, err := d.uint(size)
+		shift := 64 - 8*size
+		return head{kind: kindInt, i: int64(u<<shift) >> shift}, err
+	case mpFloat:
+		u, err := d.uint(4)
+		return head{kind: kindFloat, f: float64(math.Float32frombits(uint32(u)))}, err
+	case mpDouble:
+		u, err := d.uint(8)
+		return head{kind: kindFloat, f: math.Float64frombits(u)}, err
+	case fixExt1, fixExt2, fixExt4, fixExt8, fixExt16:
+		h = head{kind: kindExt, n: 1 << (c - fixExt1)}
+	case mpExt8, mpExt16, mpExt32:
+		if h, err = sized(kindExt, 1<<(c-mpExt8)); err != nil {
+			return h, err
+		}
+	default:
+		return h, fmt.Errorf("nullmsgpack: invalid format byte 0x%02x", c)
+	}
+
+	t, err := d.read(1)
+	if err != nil {
+		return h, err
+	}
+	h.ext = t[0]
+	return h, nil
+}
+
+// checkLen rejects a length of n items that cannot fit in the rest of the
+// data.
+func (d *decoder) checkLen(n uint64) error {
+	if !codec.Fits(n, d.data[d.off:]) {
+		return errEOF
+	}
+	return nil
+}
+
+// --- Typed decoding ---
+
+// decode decodes the next object into the addressable v.
+func (d *decoder) decode(v reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	c, err := d.peek()
+	if err != nil {
+		return err
+	}
+	t := v.Type()
+
+	if bridge.IsValue(t) {
+		if c == mpNil {
+			d.off++
+			bridge.Set(v, reflect.Value{})
+			return nil
+		}
+		return codec.DecodeValue(v, d.decode)
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		start := d.off
+		if err := d.skip(); err != nil {
+			return err
+		}
+		return v.Addr().Interface().(Unmarshaler).UnmarshalMsgpack(d.data[start:d.off])
+	}
+
+	if c == mpNil {
+		d.off++
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+			v.SetZero()
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return d.decode(v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			if !v.IsNil() && v.Elem().Kind() == reflect.Pointer {
+				return d.decode(v.Elem().Elem())
+			}
+			return fmt.Errorf("nullmsgpack: cannot decode into %s", t)
+		}
+		x, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(x))
+		}
+		return nil
+	}
+
+	h, err := d.head()
+	if err != nil {
+		return err
+	}
+	if t == timeType {
+		tm, err := d.readTimestamp(h)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	switch h.kind {
+	case kindBool:
+		if v.Kind() != reflect.Bool {
+			return typeError(h.kind, t)
+		}
+		v.SetBool(h.b)
+		return nil
+	case kindUint:
+		return setUint(v, h.n)
+	case kindInt:
+		return setInt(v, h.i)
+	case kindFloat:
+		if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+			return typeError(h.kind, t)
+		}
+		v.SetFloat(h.f)
+		return nil
+	case kindStr, kindBin:
+		s, err := d.read(h.n)
+		if err != nil {
+			return err
+		}
+		return setString(v, h.kind, s)
+	case kindArray:
+		return d.decodeArray(v, h.n)
+	case kindMap:
+		return d.decodeMap(v, h.n)
+	}
+	return typeError(h.kind, t)
+}
+
+func (d *decoder) decodeArray(v reflect.Value, n uint64) error {
+	if err := d.checkLen(n); err != nil {
+		return err
+	}
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(t, int(n), int(n))
+		for i := range int(n) {
+			if err := d.decode(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Array:
+		for i := range int(n) {
+			if i >= v.Len() {
+				if err := d.skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		for i := int(n); i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+		return nil
+	}
+	return typeError(kindArray, t)
+}
+
+func (d *decoder) decodeMap(v reflect.Value, n uint64) error {
+	if err := d.checkLen(n); err != nil {
+		return err
+	}
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := codec.Fields(t, "msgpack")
+		for range n {
+			key, isStr, err := d.readKey()
+			if err != nil {
+				return err
+			}
+			fv, found, err := codec.StructField(v, fields, key)
+			if err != nil {
+				return fmt.Errorf("nullmsgpack: %w", err)
+			}
+			if !isStr || !found {
+				if err := d.skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.decode(fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		for range n {
+			k := reflect.New(t.Key()).Elem()
+			if err := d.decode(k); err != nil {
+				return err
+			}
+			e := reflect.New(t.Elem()).Elem()
+			if err := d.decode(e); err != nil {
+				return err
+			}
+			if err := codec.SetMapIndex(v, k, e); err != nil {
+				return fmt.Errorf("nullmsgpack: %w", err)
+			}
+		}
+		return nil
+	}
+	return typeError(kindMap, t)
+}
+
+// readKey reads a struct key. Keys that are not strings are skipped and
+// reported with isStr false.
+func (d *decoder) readKey() (key string, isStr bool, err error) {
+	start := d.off
+	h, err := d.head()
+	if err != nil {
+		return "", false, err
+	}
+	if h.kind != kindStr {
+		d.off = start
+		return "", false, d.skip()
+	}
+	s, err := d.read(h.n)
+	return string(s), true, err
+}
+
+// readTimestamp reads the data of a timestamp extension in its 32, 64 or
+// 96-bit form.
+func (d *decoder) readTimestamp(h head) (time.Time, error) {
+	if h.kind != kindExt {
+		return time.Time{}, typeError(h.kind, timeType)
+	}
+	if h.ext != extTimestamp {
+		return time.Time{}, fmt.Errorf("nullmsgpack: cannot decode extension type %d into time.Time", int8(h.ext))
+	}
+	p, err := d.read(h.n)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch len(p) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(p)), 0).UTC(), nil
+	case 8:
+		u := binary.BigEndian.Uint64(p)
+		return timestamp(int64(u&(1<<34-1)), u>>34)
+	case 12:
+		return timestamp(int64(binary.BigEndian.Uint64(p[4:])), uint64(binary.BigEndian.Uint32(p)))
+	}
+	return time.Time{}, fmt.Errorf("nullmsgpack: invalid timestamp length %d", len(p))
+}
+
+func timestamp(sec int64, nsec uint64) (time.Time, error) {
+	if nsec >= 1e9 {
+		return time.Time{}, fmt.Errorf("nullmsgpack: invalid timestamp nanoseconds %d", nsec)
+	}
+	return time.Unix(sec, int64(nsec)).UTC(), nil
+}
+
+func setUint(v reflect.Value, n uint64) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n > math.MaxInt64 || v.OverflowInt(int64(n)) {
+			return overflowError(fmt.Sprint(n), v.Type())
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.OverflowUint(n) {
+			return overflowError(fmt.Sprint(n), v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(n))
+	default:
+		return typeError(kindUint, v.Type())
+	}
+	return nil
+}
+
+func setInt(v reflect.Value, i int64) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(i) {
+			return overflowError(fmt.Sprint(i), v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if i < 0 || v.OverflowUint(uint64(i)) {
+			return overflowError(fmt.Sprint(i), v.Type())
+		}
+		v.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(i))
+	default:
+		return typeError(kindInt, v.Type())
+	}
+	return nil
+}
+
+// setString stores a string or binary in a string, []byte or [N]byte.
+func setString(v reflect.Value, k kind, s []byte) error {
+	t := v.Type()
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(string(s))
+		return nil
+	case v.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		v.SetBytes(bytes.Clone(s))
+		return nil
+	case v.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8:
+		if len(s) != v.Len() {
+			return fmt.Errorf("nullmsgpack: cannot decode %d bytes into %s", len(s), t)
+		}
+		reflect.Copy(v, reflect.ValueOf(s))
+		return nil
+	}
+	return typeError(k, t)
+}
+
+// --- Untyped decoding ---
+
+// decodeAny decodes the next object into the Go value for an empty
+// interface, as described on Unmarshal.
+func (d *decoder) decodeAny() (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	h, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch h.kind {
+	case kindNil:
+		return nil, nil
+	case kindBool:
+		return h.b, nil
+	case kindUint:
+		if h.n > math.MaxInt64 {
+			return h.n, nil
+		}
+		return int64(h.n), nil
+	case kindInt:
+		return h.i, nil
+	case kindFloat:
+		return h.f, nil
+	case kindStr:
+		s, err := d.read(h.n)
+		return string(s), err
+	case kindBin:
+		s, err := d.read(h.n)
+		return bytes.Clone(s), err
+	case kindArray:
+		if err := d.checkLen(h.n); err != nil {
+			return nil, err
+		}
+		out := make([]any, 0, h.n)
+		for range h.n {
+			x, err := d.decodeAny()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, x)
+		}
+		return out, nil
+	case kindMap:
+		return d.decodeAnyMap(h.n)
+	}
+	return d.readTimestamp(h)
+}
+
+func (d *decoder) decodeAnyMap(n uint64) (any, error) {
+	if err := d.checkLen(n); err != nil {
+		return nil, err
+	}
+	keys, values := make([]any, 0, n), make([]any, 0, n)
+	for range n {
+		k, err := d.decodeAny()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decodeAny()
+		if err != nil {
+			return nil, err
+		}
+		keys, values = append(keys, k), append(values, v)
+	}
+
+	m, err := codec.AnyMap(keys, values)
+	if err != nil {
+		return nil, fmt.Errorf("nullmsgpack: %w", err)
+	}
+	return m, nil
+}
+
+// skip advances past the next object.
+func (d *decoder) skip() error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	h, err := d.head()
+	if err != nil {
+		return err
+	}
+	switch h.kind {
+	case kindStr, kindBin, kindExt:
+		_, err := d.read(h.n)
+		return err
+	case kindArray, kindMap:
+		if err := d.checkLen(h.n); err != nil {
+			return err
+		}
+		n := h.n
+		if h.kind == kindMap {
+			n *= 2
+		}
+		for range n {
+			if err := d.skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func typeError(k kind, t reflect.Type) error {
+	return fmt.Errorf("nullmsgpack: cannot decode %s into %s", k, t)
+}
+
+func overflowError(n string, t reflect.Type) error {
+	return fmt.Errorf("nullmsgpack: %s overflows %s", n, t)
+}
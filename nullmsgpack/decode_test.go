@@ -0,0 +1,336 @@
+package nullmsgpack
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestUnmarshal_States(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want null.Value[string]
+	}{
+		"absent":     {"80", null.Value[string]{}},
+		"nil":        {"81a46e616d65c0", null.NewNull[string]()},
+		"value":      {"81a46e616d65a161", null.New("a")},
+		"empty":      {"81a46e616d65a0", null.New("")},
+		"str8 key":   {"81d9046e616d65a161", null.New("a")},
+		"other case": {"81a44e414d45a161", null.New("a")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var u user
+			require.NoError(t, Unmarshal(unhex(t, tt.data), &u))
+			assert.Equal(t, tt.want, u.Name)
+		})
+	}
+}
+
+func TestUnmarshal_Any(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want any
+	}{
+		"positive fixint": {"7f", int64(127)},
+		"negative fixint": {"e0", int64(-32)},
+		"uint8":           {"ccff", int64(255)},
+		"uint16":          {"cd0100", int64(256)},
+		"uint32":          {"ceffffffff", int64(math.MaxUint32)},
+		"uint64":          {"cfffffffffffffffff", uint64(math.MaxUint64)},
+		"int8":            {"d080", int64(-128)},
+		"int16":           {"d18000", int64(math.MinInt16)},
+		"int32":           {"d280000000", int64(math.MinInt32)},
+		"int64":           {"d38000000000000000", int64(math.MinInt64)},
+		"float32":         {"ca3fc00000", 1.5},
+		"float64":         {"cb3ff8000000000000", 1.5},
+		"nil":             {"c0", nil},
+		"true":            {"c3", true},
+		"fixstr":          {"a3616263", "abc"},
+		"str16":           {"da000161", "a"},
+		"str32":           {"db0000000161", "a"},
+		"bin16":           {"c500020102", []byte{1, 2}},
+		"bin32":           {"c60000000101", []byte{1}},
+		"array":           {"92a161c0", []any{"a", nil}},
+		"array32":         {"dd0000000101", []any{int64(1)}},
+		"map":             {"81a16101", map[string]any{"a": int64(1)}},
+		"map16":           {"de000101c3", map[any]any{int64(1): true}},
+		"map32":           {"df00000001a161c2", map[string]any{"a": false}},
+		"timestamp 32":    {"d6ff00000001", time.Unix(1, 0).UTC()},
+		"timestamp 64":    {"d7ff0000000400000001", time.Unix(1, 1).UTC()},
+		"timestamp 96":    {"c70cff00000001ffffffffffffffff", time.Unix(-1, 1).UTC()},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got any
+			require.NoError(t, Unmarshal(unhex(t, tt.data), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		into any
+		want string
+	}{
+		"empty":            {"", new(any), "unexpected end of data"},
+		"truncated uint":   {"cd01", new(any), "unexpected end of data"},
+		"truncated str":    {"a261", new(string), "unexpected end of data"},
+		"huge array":       {"ddffffffff", new([]int), "unexpected end of data"},
+		"trailing":         {"0000", new(int), "unexpected data after top-level object"},
+		"never used":       {"c1", new(any), "invalid format byte 0xc1"},
+		"type mismatch":    {"a161", new(int), "cannot decode string into int"},
+		"negative uint":    {"ff", new(uint), "-1 overflows uint"},
+		"overflow":         {"cd0100", new(int8), "256 overflows int8"},
+		"uint64 overflow":  {"cfffffffffffffffff", new(int64), "overflows int64"},
+		"float into int":   {"ca3fc00000", new(int), "cannot decode float into int"},
+		"bool into string": {"c3", new(string), "cannot decode bool into string"},
+		"array into map":   {"90", new(map[string]int), "cannot decode array into map[string]int"},
+		"map into slice":   {"80", new([]int), "cannot decode map into []int"},
+		"array length":     {"c403010203", new([2]byte), "cannot decode 3 bytes into [2]uint8"},
+		"ext into int":     {"d40100", new(int), "cannot decode extension into int"},
+		"other ext":        {"d40100", new(time.Time), "cannot decode extension type 1 into time.Time"},
+		"other ext any":    {"d40100", new(any), "extension type 1"},
+		"string into time": {"a161", new(time.Time), "cannot decode string into time.Time"},
+		"bad timestamp":    {"d5ff0000", new(time.Time), "invalid timestamp length 2"},
+		"bad nanoseconds":  {"d7ffffffffff00000000", new(time.Time), "invalid timestamp nanoseconds"},
+		"unhashable key":   {"81c401f5c3", new(any), "unhashable map key of type []uint8"},
+		"unhashable into":  {"819001", new(map[any]int), "unhashable map key"},
+		"unhashable field": {"8181a1589001", new(map[struct{ X any }]int), "unhashable map key of type struct { X interface {} }"},
+		"into value":       {"a161", new(null.Value[int]), "cannot decode string into int"},
+		"not a pointer":    {"00", 0, "requires a non-nil pointer"},
+		"nil pointer":      {"00", (*int)(nil), "requires a non-nil pointer"},
+		"unmarshaler":      {"a161", new(version), "bad version"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, Unmarshal(unhex(t, tt.data), tt.into), tt.want)
+		})
+	}
+}
+
+type DecodeSuite struct {
+	suite.Suite
+}
+
+func TestDecodeSuite(t *testing.T) {
+	suite.Run(t, new(DecodeSuite))
+}
+
+func (s *DecodeSuite) TestRoundTrip() {
+	in := user{
+		Name:     null.New("Alice"),
+		Email:    null.NewNull[string](),
+		Age:      null.New(int8(-5)),
+		Score:    null.New(float32(9.5)),
+		Avatar:   null.New([]byte{0xca, 0xfe}),
+		Seen:     null.New(time.Date(2024, 1, 15, 10, 0, 0, 123, time.UTC)),
+		Address:  null.New(address{City: null.New("Paris"), Zip: null.NewNull[string]()}),
+		Aliases:  []null.Value[string]{null.New("al"), null.NewNull[string]()},
+		Settings: map[string]null.Value[int]{"theme": null.New(2), "lang": null.NewNull[int]()},
+		Plain:    "x",
+		Audit:    Audit{UpdatedBy: null.New("ops")},
+	}
+	b, err := Marshal(in)
+	s.Require().NoError(err)
+
+	var out user
+	s.Require().NoError(Unmarshal(b, &out))
+	s.Equal(in, out)
+}
+
+func (s *DecodeSuite) TestUnsetOmittedFromMaps() {
+	b, err := Marshal(map[string]null.Value[int]{"a": {}, "b": null.New(1)})
+	s.Require().NoError(err)
+
+	var out map[string]null.Value[int]
+	s.Require().NoError(Unmarshal(b, &out))
+	s.Equal(map[string]null.Value[int]{"b": null.New(1)}, out)
+}
+
+func (s *DecodeSuite) TestIntegerWidths() {
+	for _, v := range []int64{0, 1, 127, 128, 255, 256, 65535, 65536, math.MaxInt32, math.MaxInt32 + 1, math.MaxInt64,
+		-1, -32, -33, -128, -129, math.MinInt16, math.MinInt16 - 1, math.MinInt32, math.MinInt32 - 1, math.MinInt64} {
+		b, err := Marshal(v)
+		s.Require().NoError(err)
+		var got int64
+		s.Require().NoError(Unmarshal(b, &got), v)
+		s.Equal(v, got)
+
+		var f float64
+		s.Require().NoError(Unmarshal(b, &f), v)
+		s.Equal(float64(v), f)
+	}
+
+	var u8 uint8
+	s.Require().NoError(Unmarshal(unhex(s.T(), "d07f"), &u8))
+	s.Equal(uint8(127), u8)
+}
+
+type base struct {
+	ID null.Value[int] `msgpack:"id"`
+}
+
+type Kind struct {
+	Kind null.Value[string] `msgpack:"kind"`
+}
+
+func (s *DecodeSuite) TestEmbedded() {
+	// Like encoding/json, unexported embedded structs and embedded struct
+	// pointers are flattened.
+	type doc struct {
+		base
+		*Kind
+		Name null.Value[string] `msgpack:"name"`
+	}
+	in := doc{base: base{ID: null.New(3)}, Kind: &Kind{Kind: null.New("a")}, Name: null.New("x")}
+	b, err := Marshal(in)
+	s.Require().NoError(err)
+
+	var got map[string]any
+	s.Require().NoError(Unmarshal(b, &got))
+	s.Equal(map[string]any{"id": int64(3), "kind": "a", "name": "x"}, got)
+
+	var out doc
+	s.Require().NoError(Unmarshal(b, &out))
+	s.Equal(in, out)
+
+	// A nil embedded pointer contributes no keys.
+	b, err = Marshal(doc{Name: null.New("x")})
+	s.Require().NoError(err)
+	got = nil
+	s.Require().NoError(Unmarshal(b, &got))
+	s.Equal(map[string]any{"name": "x"}, got)
+}
+
+func (s *DecodeSuite) TestNestedPointersAndSlices() {
+	type doc struct {
+		Where  *address              `msgpack:"where"`
+		Grid   [][]null.Value[int]   `msgpack:"grid"`
+		Fixed  [2]null.Value[int]    `msgpack:"fixed"`
+		Maybe  null.Value[*address]  `msgpack:"maybe"`
+		Nested null.Value[[]address] `msgpack:"nested"`
+	}
+	in := doc{
+		Where:  &address{City: null.NewNull[string]()},
+		Grid:   [][]null.Value[int]{{null.New(1)}, {null.NewNull[int]()}},
+		Fixed:  [2]null.Value[int]{null.New(1), null.NewNull[int]()},
+		Maybe:  null.New(&address{Zip: null.New("2")}),
+		Nested: null.New([]address{{City: null.New("a")}, {}}),
+	}
+	b, err := Marshal(in)
+	s.Require().NoError(err)
+
+	var out doc
+	s.Require().NoError(Unmarshal(b, &out))
+	s.Equal(in, out)
+}
+
+func (s *DecodeSuite) TestNilIntoPlainValues() {
+	n, p, m := 5, new(int), map[string]int{"a": 1}
+	s.Require().NoError(Unmarshal(unhex(s.T(), "c0"), &n))
+	s.Require().NoError(Unmarshal(unhex(s.T(), "c0"), &p))
+	s.Require().NoError(Unmarshal(unhex(s.T(), "c0"), &m))
+	s.Equal(5, n)
+	s.Nil(p)
+	s.Nil(m)
+}
+
+func (s *DecodeSuite) TestUnknownKeys() {
+	// {"x": [1, {"y": ext}], 1: 2, "name": "a"}
+	var u user
+	s.Require().NoError(Unmarshal(unhex(s.T(), "83a178920181a179d401000102a46e616d65a161"), &u))
+	s.Equal(null.New("a"), u.Name)
+}
+
+func (s *DecodeSuite) TestArrayLengths() {
+	var short [1]int
+	s.Require().NoError(Unmarshal(unhex(s.T(), "93010203"), &short))
+	s.Equal([1]int{1}, short)
+
+	long := [3]int{7, 8, 9}
+	s.Require().NoError(Unmarshal(unhex(s.T(), "9101"), &long))
+	s.Equal([3]int{1, 0, 0}, long)
+}
+
+func (s *DecodeSuite) TestStringAndBinary() {
+	var str string
+	s.Require().NoError(Unmarshal(unhex(s.T(), "c4026869"), &str))
+	s.Equal("hi", str)
+
+	var bin []byte
+	s.Require().NoError(Unmarshal(unhex(s.T(), "a26869"), &bin))
+	s.Equal([]byte("hi"), bin)
+}
+
+func (s *DecodeSuite) TestUnmarshaler() {
+	var v null.Value[version]
+	s.Require().NoError(Unmarshal(unhex(s.T(), "a3312e32"), &v))
+	s.Equal(null.New(version{1, 2}), v)
+}
+
+func (s *DecodeSuite) TestInterfaceWithPointer() {
+	var name nameStringer
+	var st fmt.Stringer = &name
+	s.Require().NoError(Unmarshal(unhex(s.T(), "a161"), &st))
+	s.Equal(nameStringer("a"), name)
+
+	var empty fmt.Stringer
+	s.ErrorContains(Unmarshal(unhex(s.T(), "a161"), &empty), "cannot decode into fmt.Stringer")
+}
+
+type nameStringer string
+
+func (n *nameStringer) String() string { return string(*n) }
+
+func (s *DecodeSuite) TestDepthLimit() {
+	data := make([]byte, codec.MaxDepth+1)
+	for i := range data {
+		data[i] = 0x91
+	}
+	var v any
+	s.ErrorContains(Unmarshal(append(data, 0), &v), "exceeded max nesting depth")
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, s := range []string{
+		"81a46e616d65c0",
+		"83a178920181a179d401000102a46e616d65a161",
+		"c70cff00000001ffffffffffffffff",
+		"de000101c3",
+		"8181a1589001",
+	} {
+		b, _ := hex.DecodeString(s)
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var u user
+		if Unmarshal(data, &u) == nil {
+			b, err := Marshal(u)
+			require.NoError(t, err)
+			var again user
+			require.NoError(t, Unmarshal(b, &again))
+		}
+		var v any
+		_ = Unmarshal(data, &v)
+		var m map[struct{ X any }]int
+		_ = Unmarshal(data, &m)
+	})
+}
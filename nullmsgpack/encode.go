@@ -0,0 +1,235 @@
+package nullmsgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/codec"
+)
+
+type encoder struct {
+	depth codec.Depth
+}
+
+// Marshal returns the MessagePack encoding of v.
+func Marshal(v any) ([]byte, error) {
+	e := &encoder{}
+	return e.encode(nil, reflect.ValueOf(v))
+}
+
+func (e *encoder) encode(b []byte, v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return append(b, mpNil), nil
+	}
+	if err := e.depth.Enter(); err != nil {
+		return nil, fmt.Errorf("nullmsgpack: %w", err)
+	}
+	defer e.depth.Leave()
+
+	t := v.Type()
+	if bridge.IsValue(t) {
+		if state, x := codec.Value(v); state == null.Valid {
+			return e.encode(b, x)
+		}
+		return append(b, mpNil), nil
+	}
+	if t.Implements(marshalerType) {
+		if (t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface) && v.IsNil() {
+			return append(b, mpNil), nil
+		}
+		return appendMarshaler(b, v.Interface().(Marshaler))
+	}
+	if v.CanAddr() && reflect.PointerTo(t).Implements(marshalerType) {
+		return appendMarshaler(b, v.Addr().Interface().(Marshaler))
+	}
+	if t == timeType {
+		return appendTimestamp(b, v.Interface().(time.Time)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, mpTrue), nil
+		}
+		return append(b, mpFalse), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendInt(b, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUint(b, v.Uint()), nil
+	case reflect.Float32:
+		return binary.BigEndian.AppendUint32(append(b, mpFloat), math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return binary.BigEndian.AppendUint64(append(b, mpDouble), math.Float64bits(v.Float())), nil
+	case reflect.String:
+		b = appendLen(b, v.Len(), fixStr, 32, mpStr8, mpStr16, mpStr32)
+		return append(b, v.String()...), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return append(b, mpNil), nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			b = appendLen(b, v.Len(), 0, 0, mpBin8, mpBin16, mpBin32)
+			return append(b, v.Bytes()...), nil
+		}
+		return e.encodeArray(b, v)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			b = appendLen(b, v.Len(), 0, 0, mpBin8, mpBin16, mpBin32)
+			for i := range v.Len() {
+				b = append(b, byte(v.Index(i).Uint()))
+			}
+			return b, nil
+		}
+		return e.encodeArray(b, v)
+	case reflect.Map:
+		if v.IsNil() {
+			return append(b, mpNil), nil
+		}
+		return e.encodeMap(b, v)
+	case reflect.Struct:
+		return e.encodeStruct(b, v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return append(b, mpNil), nil
+		}
+		return e.encode(b, v.Elem())
+	}
+	return nil, fmt.Errorf("nullmsgpack: unsupported type %s", t)
+}
+
+func (e *encoder) encodeArray(b []byte, v reflect.Value) ([]byte, error) {
+	b = appendLen(b, v.Len(), fixArray, 16, 0, mpArr16, mpArr32)
+	for i := range v.Len() {
+		var err error
+		if b, err = e.encode(b, v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// encodeMap writes the entries of v sorted by the bytes of their encoded
+// keys, leaving out Unset values.
+func (e *encoder) encodeMap(b []byte, v reflect.Value) ([]byte, error) {
+	type entry struct{ key, value []byte }
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		if codec.IsUnset(iter.Value()) {
+			continue
+		}
+		key, err := e.encode(nil, iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		value, err := e.encode(nil, iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key, value})
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return bytes.Compare(a.key, b.key) })
+
+	b = appendLen(b, len(entries), fixMap, 16, 0, mpMap16, mpMap32)
+	for _, en := range entries {
+		b = append(append(b, en.key...), en.value...)
+	}
+	return b, nil
+}
+
+// encodeStruct writes v as a map keyed by field name, in field order.
+// Unset Values and empty omitempty fields are left out.
+func (e *encoder) encodeStruct(b []byte, v reflect.Value) ([]byte, error) {
+	var body []byte
+	n := 0
+	for name, fv := range codec.StructFields(v, "msgpack") {
+		body = appendLen(body, len(name), fixStr, 32, mpStr8, mpStr16, mpStr32)
+		body = append(body, name...)
+		var err error
+		if body, err = e.encode(body, fv); err != nil {
+			return nil, err
+		}
+		n++
+	}
+	b = appendLen(b, n, fixMap, 16, 0, mpMap16, mpMap32)
+	return append(b, body...), nil
+}
+
+// appendTimestamp writes t with the timestamp extension in the smallest of
+// its 32, 64 and 96-bit forms.
+func appendTimestamp(b []byte, t time.Time) []byte {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	switch {
+	case nsec == 0 && sec >= 0 && sec <= math.MaxUint32:
+		b = append(b, fixExt4, extTimestamp)
+		return binary.BigEndian.AppendUint32(b, uint32(sec))
+	case sec >= 0 && sec < 1<<34:
+		b = append(b, fixExt8, extTimestamp)
+		return binary.BigEndian.AppendUint64(b, nsec<<34|uint64(sec))
+	}
+	b = append(b, mpExt8, 12, extTimestamp)
+	b = binary.BigEndian.AppendUint32(b, uint32(nsec))
+	return binary.BigEndian.AppendUint64(b, uint64(sec))
+}
+
+func appendMarshaler(b []byte, m Marshaler) ([]byte, error) {
+	data, err := m.MarshalMsgpack()
+	if err != nil {
+		return nil, err
+	}
+	return append(b, data...), nil
+}
+
+// appendLen writes the header of a string, binary, array or map of length
+// n: the fix format (when fixMax is not 0 and n < fixMax) or the 8, 16 or
+// 32-bit format. Formats without an 8-bit variant pass 0 for f8.
+func appendLen(b []byte, n int, fix byte, fixMax int, f8, f16, f32 byte) []byte {
+	switch {
+	case n < fixMax:
+		return append(b, fix|byte(n))
+	case f8 != 0 && n <= math.MaxUint8:
+		return append(b, f8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, f16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, f32), uint32(n))
+}
+
+// appendInt writes i in its smallest format. Non-negative values use the
+// unsigned formats.
+func appendInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return appendUint(b, uint64(i))
+	case i >= -32:
+		return append(b, byte(i))
+	case i >= math.MinInt8:
+		return append(b, mpInt8, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, mpInt16), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, mpInt32), uint32(i))
+	}
+	return binary.BigEndian.AppendUint64(append(b, mpInt64), uint64(i))
+}
+
+func appendUint(b []byte, u uint64) []byte {
+	switch {
+	case u <= 0x7f:
+		return append(b, byte(u))
+	case u <= math.MaxUint8:
+		return append(b, mpUint8, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, mpUint16), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, mpUint32), uint32(u))
+	}
+	return binary.BigEndian.AppendUint64(append(b, mpUint64), u)
+}
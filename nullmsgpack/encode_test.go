@@ -0,0 +1,200 @@
+package nullmsgpack
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type address struct {
+	City null.Value[string] `msgpack:"city"`
+	Zip  null.Value[string] `msgpack:"zip"`
+}
+
+type Audit struct {
+	UpdatedBy null.Value[string] `msgpack:"updated_by"`
+}
+
+type user struct {
+	Name     null.Value[string]         `msgpack:"name"`
+	Email    null.Value[string]         `msgpack:"email"`
+	Age      null.Value[int8]           `msgpack:"age"`
+	Score    null.Value[float32]        `msgpack:"score"`
+	Avatar   null.Value[[]byte]         `msgpack:"avatar"`
+	Seen     null.Value[time.Time]      `msgpack:"seen"`
+	Address  null.Value[address]        `msgpack:"address"`
+	Aliases  []null.Value[string]       `msgpack:"aliases,omitempty"`
+	Settings map[string]null.Value[int] `msgpack:"settings,omitempty"`
+	Plain    string                     `json:"plain,omitempty"`
+	Skipped  null.Value[string]         `msgpack:"-"`
+	Untagged null.Value[bool]
+	Audit
+}
+
+func TestMarshal_States(t *testing.T) {
+	tests := map[string]struct {
+		v    any
+		want string
+	}{
+		"unset":         {null.Value[int]{}, "c0"},
+		"null":          {null.NewNull[int](), "c0"},
+		"valid":         {null.New(1), "01"},
+		"valid zero":    {null.New(""), "a0"},
+		"unset field":   {address{}, "80"},
+		"null field":    {address{City: null.NewNull[string]()}, "81a463697479c0"},
+		"valid field":   {address{Zip: null.New("1")}, "81a37a6970a131"},
+		"unset in map":  {map[string]null.Value[int]{"a": {}, "b": null.NewNull[int](), "c": null.New(1)}, "82a162c0a16301"},
+		"unset element": {[]null.Value[int]{null.New(1), {}, null.NewNull[int]()}, "9301c0c0"},
+		"nested":        {null.New(address{City: null.New("a")}), "81a463697479a161"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := Marshal(tt.v)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(b))
+		})
+	}
+}
+
+func TestMarshal_Formats(t *testing.T) {
+	tests := map[string]struct {
+		v    any
+		want string
+	}{
+		"positive fixint":  {127, "7f"},
+		"uint8":            {128, "cc80"},
+		"uint16":           {uint16(256), "cd0100"},
+		"uint32":           {65536, "ce00010000"},
+		"uint64":           {uint64(1 << 32), "cf0000000100000000"},
+		"max uint64":       {uint64(math.MaxUint64), "cfffffffffffffffff"},
+		"negative fixint":  {-1, "ff"},
+		"min fixint":       {int8(-32), "e0"},
+		"int8":             {-33, "d0df"},
+		"int16":            {int16(-129), "d1ff7f"},
+		"int32":            {int32(-32769), "d2ffff7fff"},
+		"int64":            {int64(math.MinInt64), "d38000000000000000"},
+		"float32":          {float32(1.5), "ca3fc00000"},
+		"float64":          {1.5, "cb3ff8000000000000"},
+		"nil":              {nil, "c0"},
+		"false":            {false, "c2"},
+		"true":             {true, "c3"},
+		"fixstr":           {"a", "a161"},
+		"str8":             {strings.Repeat("a", 32), "d920" + strings.Repeat("61", 32)},
+		"str16":            {strings.Repeat("a", 256), "da0100" + strings.Repeat("61", 256)},
+		"bin8":             {[]byte{1}, "c40101"},
+		"empty bin":        {[]byte{}, "c400"},
+		"byte array":       {[2]byte{1, 2}, "c4020102"},
+		"nil bin":          {[]byte(nil), "c0"},
+		"fixarray":         {[]int{1, 2}, "920102"},
+		"array16":          {make([]int, 16), "dc0010" + strings.Repeat("00", 16)},
+		"fixmap":           {map[string]int{"a": 1}, "81a16101"},
+		"map sorted":       {map[int]string{2: "b", 1: "a"}, "8201a16102a162"},
+		"nil map":          {map[string]int(nil), "c0"},
+		"timestamp 32":     {time.Unix(1, 0), "d6ff00000001"},
+		"timestamp 64":     {time.Unix(1, 1), "d7ff0000000400000001"},
+		"timestamp 96":     {time.Unix(-1, 0), "c70cff00000000ffffffffffffffff"},
+		"pointer":          {new(int), "00"},
+		"nil pointer":      {(*int)(nil), "c0"},
+		"interface in map": {map[string]any{"a": nil}, "81a161c0"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := Marshal(tt.v)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hex.EncodeToString(b))
+		})
+	}
+}
+
+// version is encoded with its own MarshalMsgpack as a "major.minor" string.
+type version struct{ Major, Minor uint8 }
+
+func (v version) MarshalMsgpack() ([]byte, error) {
+	if v.Major == 0 && v.Minor == 0 {
+		return nil, errors.New("empty version")
+	}
+	return Marshal(string([]byte{'0' + v.Major, '.', '0' + v.Minor}))
+}
+
+func (v *version) UnmarshalMsgpack(data []byte) error {
+	var s string
+	if err := Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if len(s) != 3 || s[1] != '.' {
+		return errors.New("bad version")
+	}
+	v.Major, v.Minor = s[0]-'0', s[2]-'0'
+	return nil
+}
+
+type EncodeSuite struct {
+	suite.Suite
+}
+
+func TestEncodeSuite(t *testing.T) {
+	suite.Run(t, new(EncodeSuite))
+}
+
+func (s *EncodeSuite) TestStruct() {
+	b, err := Marshal(user{
+		Name:     null.New("Alice"),
+		Email:    null.NewNull[string](),
+		Plain:    "x",
+		Skipped:  null.New("ignored"),
+		Untagged: null.New(true),
+		Audit:    Audit{UpdatedBy: null.New("ops")},
+	})
+	s.Require().NoError(err)
+
+	var got map[string]any
+	s.Require().NoError(Unmarshal(b, &got))
+	s.Equal(map[string]any{
+		"name":       "Alice",
+		"email":      nil,
+		"plain":      "x",
+		"Untagged":   true,
+		"updated_by": "ops",
+	}, got)
+}
+
+func (s *EncodeSuite) TestOmitEmpty() {
+	b, err := Marshal(user{Aliases: []null.Value[string]{}, Settings: map[string]null.Value[int]{}})
+	s.Require().NoError(err)
+	s.Equal("80", hex.EncodeToString(b))
+}
+
+func (s *EncodeSuite) TestMarshaler() {
+	b, err := Marshal(null.New(version{1, 2}))
+	s.Require().NoError(err)
+	s.Equal("a3312e32", hex.EncodeToString(b))
+
+	_, err = Marshal([]version{{}})
+	s.ErrorContains(err, "empty version")
+
+	b, err = Marshal((*version)(nil))
+	s.Require().NoError(err)
+	s.Equal("c0", hex.EncodeToString(b))
+}
+
+func (s *EncodeSuite) TestErrors() {
+	_, err := Marshal(make(chan int))
+	s.ErrorContains(err, "nullmsgpack: unsupported type chan int")
+
+	_, err = Marshal(null.New(complex(1, 2)))
+	s.ErrorContains(err, "unsupported type complex128")
+
+	type node struct{ Next any }
+	n := &node{}
+	n.Next = n
+	_, err = Marshal(n)
+	s.ErrorContains(err, "exceeded max nesting depth")
+}
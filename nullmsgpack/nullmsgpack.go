@@ -0,0 +1,100 @@
+// Package nullmsgpack encodes and decodes MessagePack with support for the
+// three states of null.Value, using only the standard library.
+//
+// MessagePack has nil but no undefined value, so Unset is expressed by
+// leaving the key out:
+//
+//	null.Value[T]{}     omitted from structs and maps (nil elsewhere)
+//	null.NewNull[T]()   nil
+//	null.New(x)         x encoded as T
+//
+// When decoding, a struct key that is absent leaves the field Unset, nil
+// makes it Null and anything else is decoded into T:
+//
+//	type Request struct {
+//	    Name  null.Value[string] `msgpack:"name"`
+//	    Email null.Value[string] `msgpack:"email"`
+//	    Age   null.Value[int]    `msgpack:"age"`
+//	}
+//
+//	data, err := nullmsgpack.Marshal(req)
+//	err = nullmsgpack.Unmarshal(data, &req)
+//
+// Integers of every width are written in their smallest format, floats
+// keep their width, strings use the str formats and []byte the bin
+// formats. time.Time uses the timestamp extension (type -1). Arrays, maps,
+// nested structs and pointers map onto the matching MessagePack types.
+// Struct fields are named by their `msgpack` tag, then their `json` tag,
+// then their Go name; "-" skips a field, omitempty drops empty values and,
+// as in encoding/json, untagged embedded structs and struct pointers are
+// flattened. Map keys are sorted by their encoding, so the output is
+// deterministic. Types can control their own encoding by implementing
+// Marshaler and Unmarshaler.
+package nullmsgpack
+
+import (
+	"reflect"
+	"time"
+)
+
+// Marshaler is implemented by types that encode themselves into a single
+// MessagePack object.
+type Marshaler interface {
+	MarshalMsgpack() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that decode themselves from a single
+// MessagePack object. The data must be copied if it is kept after
+// returning.
+type Unmarshaler interface {
+	UnmarshalMsgpack(data []byte) error
+}
+
+// Format bytes. The fix formats carry their value or length in the low
+// bits of the format byte.
+const (
+	fixMap   byte = 0x80
+	fixArray byte = 0x90
+	fixStr   byte = 0xa0
+	mpNil    byte = 0xc0
+	mpFalse  byte = 0xc2
+	mpTrue   byte = 0xc3
+	mpBin8   byte = 0xc4
+	mpBin16  byte = 0xc5
+	mpBin32  byte = 0xc6
+	mpExt8   byte = 0xc7
+	mpExt16  byte = 0xc8
+	mpExt32  byte = 0xc9
+	mpFloat  byte = 0xca
+	mpDouble byte = 0xcb
+	mpUint8  byte = 0xcc
+	mpUint16 byte = 0xcd
+	mpUint32 byte = 0xce
+	mpUint64 byte = 0xcf
+	mpInt8   byte = 0xd0
+	mpInt16  byte = 0xd1
+	mpInt32  byte = 0xd2
+	mpInt64  byte = 0xd3
+	fixExt1  byte = 0xd4
+	fixExt2  byte = 0xd5
+	fixExt4  byte = 0xd6
+	fixExt8  byte = 0xd7
+	fixExt16 byte = 0xd8
+	mpStr8   byte = 0xd9
+	mpStr16  byte = 0xda
+	mpStr32  byte = 0xdb
+	mpArr16  byte = 0xdc
+	mpArr32  byte = 0xdd
+	mpMap16  byte = 0xde
+	mpMap32  byte = 0xdf
+)
+
+// extTimestamp is the extension type of the timestamp extension, -1 as a
+// signed byte.
+const extTimestamp byte = 0xff
+
+var (
+	timeType        = reflect.TypeFor[time.Time]()
+	marshalerType   = reflect.TypeFor[Marshaler]()
+	unmarshalerType = reflect.TypeFor[Unmarshaler]()
+)
@@ -0,0 +1,195 @@
+package nullpb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bjaus/null/internal/bridge"
+	"github.com/bjaus/null/internal/snake"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ErrNoFields is returned by Mask when every field of the patch is Unset,
+// since an empty field mask conventionally means "update everything".
+var ErrNoFields = errors.New("nullpb: no fields set")
+
+// Mask returns a field mask naming every Null or Valid Value field of patch,
+// a struct or pointer to a struct, in field order. Nested structs and
+// pointers to structs give dotted paths; a nil pointer contributes none.
+// Fields that are not Values, such as resource names, are ignored.
+//
+// It returns ErrNoFields when every Value field is Unset.
+func Mask(patch any) (*fieldmaskpb.FieldMask, error) {
+	rv := reflect.ValueOf(patch)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, ErrNoFields
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("nullpb: Mask expects a struct, got %T", patch)
+	}
+
+	var paths []string
+	for _, f := range maskFields(rv.Type()) {
+		if !f.value {
+			continue
+		}
+		if fv, ok := fieldByIndex(rv, f.index, false); ok && isSet(fv) {
+			paths = append(paths, f.path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, ErrNoFields
+	}
+	return &fieldmaskpb.FieldMask{Paths: paths}, nil
+}
+
+// Apply settles the state of the Value fields of the struct patch points
+// to according to mask, typically after filling patch with From:
+//   - Unset fields named by the mask, directly or through a parent path,
+//     become Null, as the client asked to clear them. Nil pointers to
+//     structs on the way are allocated.
+//   - Fields the mask does not name become Unset, even if the message set
+//     them, as the client did not ask to change them.
+//   - A "*" path names every field.
+//
+// Paths naming fields that are not Values, such as a resource name copied
+// from the message, are accepted and leave those fields alone; they are
+// the caller's to apply.
+//
+// A nil or empty mask leaves patch unchanged, so that fields present in
+// the message are updated and the rest are left alone. Apply fails if a
+// path names no field of patch; paths cannot reach inside a Value.
+func Apply(mask *fieldmaskpb.FieldMask, patch any) error {
+	rv := reflect.ValueOf(patch)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("nullpb: Apply requires a non-nil pointer to a struct, got %T", patch)
+	}
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		return nil
+	}
+
+	rv = rv.Elem()
+	fields := maskFields(rv.Type())
+	for _, p := range paths {
+		if !slices.ContainsFunc(fields, func(f maskField) bool { return covers(p, f.path) }) {
+			return fmt.Errorf("nullpb: unknown field mask path %q", p)
+		}
+	}
+
+	for _, f := range fields {
+		if !f.value {
+			continue
+		}
+		named := slices.ContainsFunc(paths, func(p string) bool { return covers(p, f.path) })
+		fv, ok := fieldByIndex(rv, f.index, named)
+		switch {
+		case !ok:
+		case !named:
+			fv.SetZero()
+		case !isSet(fv):
+			bridge.Set(fv, reflect.Value{})
+		}
+	}
+	return nil
+}
+
+// covers reports whether mask path p names the field at path, either
+// directly or through one of its parents.
+func covers(p, path string) bool {
+	return p == "*" || p == path || strings.HasPrefix(path, p+".")
+}
+
+func isSet(v reflect.Value) bool {
+	return v.Interface().(interface{ IsSet() bool }).IsSet()
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but reports false at a
+// nil pointer to a struct, or allocates it when alloc is true.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for _, x := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// --- Fields ---
+
+// maskField is a field a mask path can name. Only Value fields, marked by
+// value, take part in Mask and Apply; the others are valid paths.
+type maskField struct {
+	path  string
+	index []int
+	value bool
+}
+
+var fieldCache sync.Map // reflect.Type -> []maskField
+
+// maskFields lists the fields of struct type t with their paths.
+func maskFields(t reflect.Type) []maskField {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.([]maskField)
+	}
+	fields := collectFields(t, []reflect.Type{t})
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// collectFields walks struct type t, descending into nested structs and
+// pointers to structs. Structs without fields of their own, such as
+// time.Time, and pointers back to a struct in stack are leaves.
+func collectFields(t reflect.Type, stack []reflect.Type) []maskField {
+	var fields []maskField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		name := sf.Tag.Get("pb")
+		if name == "-" || !sf.IsExported() {
+			continue
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer && ft.Elem().Kind() == reflect.Struct && !bridge.IsValue(ft.Elem()) {
+			ft = ft.Elem()
+		}
+		isValue := bridge.IsValue(ft)
+		var nested []maskField
+		if !isValue && ft.Kind() == reflect.Struct && !slices.Contains(stack, ft) {
+			nested = collectFields(ft, append(stack, ft))
+		}
+		if len(nested) > 0 && sf.Anonymous && name == "" {
+			for _, f := range nested {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if name == "" {
+			name = snake.Lower(sf.Name)
+		}
+		if len(nested) == 0 {
+			fields = append(fields, maskField{path: name, index: []int{i}, value: isValue})
+			continue
+		}
+		for _, f := range nested {
+			f.path = name + "." + f.path
+			f.index = append([]int{i}, f.index...)
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,226 @@
+package nullpb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/snake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type Audit struct {
+	UpdatedBy null.Value[string]
+}
+
+type address struct {
+	City null.Value[string] `pb:"city"`
+	Zip  null.Value[string] `pb:"postal_code"`
+}
+
+type userPatch struct {
+	Name        string `pb:"name"`
+	DisplayName null.Value[string]
+	Email       null.Value[string] `pb:"email"`
+	Age         null.Value[int32]
+	Address     address
+	Home        null.Value[address] `pb:"home"`
+	Billing     *address            `pb:"billing"`
+	Ignored     null.Value[string]  `pb:"-"`
+	internal    null.Value[string]
+	Audit
+}
+
+type MaskSuite struct {
+	suite.Suite
+}
+
+func TestMaskSuite(t *testing.T) {
+	suite.Run(t, new(MaskSuite))
+}
+
+func (s *MaskSuite) TestMask() {
+	m, err := Mask(userPatch{
+		Name:        "users/1",
+		DisplayName: null.New("Alice"),
+		Email:       null.NewNull[string](),
+		Address:     address{Zip: null.New("75001")},
+		Home:        null.NewNull[address](),
+		Billing:     &address{City: null.New("Lyon")},
+		Ignored:     null.New("x"),
+		internal:    null.New("x"),
+		Audit:       Audit{UpdatedBy: null.New("ops")},
+	})
+	s.Require().NoError(err)
+	s.Equal([]string{"display_name", "email", "address.postal_code", "home", "billing.city", "updated_by"}, m.GetPaths())
+
+	m, err = Mask(&userPatch{Age: null.New(int32(3))})
+	s.Require().NoError(err)
+	s.Equal([]string{"age"}, m.GetPaths())
+}
+
+func (s *MaskSuite) TestMaskNoFields() {
+	_, err := Mask(userPatch{Name: "users/1", Ignored: null.New("x")})
+	s.ErrorIs(err, ErrNoFields)
+
+	_, err = Mask((*userPatch)(nil))
+	s.ErrorIs(err, ErrNoFields)
+
+	_, err = Mask(1)
+	s.EqualError(err, "nullpb: Mask expects a struct, got int")
+}
+
+func (s *MaskSuite) TestApply() {
+	// The message set display_name and age; the mask also names email and
+	// the address, which were absent, but not age.
+	p := userPatch{
+		Name:        "users/1",
+		DisplayName: From(wrapperspb.String("Alice")),
+		Age:         From(wrapperspb.Int32(3)),
+		Address:     address{City: null.New("Paris")},
+		Ignored:     null.New("x"),
+	}
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"display_name", "email", "address"}}
+	s.Require().NoError(Apply(mask, &p))
+
+	s.Equal(userPatch{
+		Name:        "users/1",
+		DisplayName: null.New("Alice"),
+		Email:       null.NewNull[string](),
+		Address:     address{City: null.New("Paris"), Zip: null.NewNull[string]()},
+		Ignored:     null.New("x"),
+	}, p)
+}
+
+func (s *MaskSuite) TestApplyRoundTrip() {
+	in := userPatch{
+		DisplayName: null.New("Alice"),
+		Email:       null.NewNull[string](),
+		Address:     address{City: null.NewNull[string]()},
+	}
+	mask, err := Mask(in)
+	s.Require().NoError(err)
+
+	// Only Valid fields survive the trip through a message.
+	out := userPatch{DisplayName: From(String(in.DisplayName)), Email: From(String(in.Email))}
+	out.Address.City = From(String(in.Address.City))
+	s.Require().NoError(Apply(mask, &out))
+	s.Equal(in, out)
+}
+
+func (s *MaskSuite) TestApplyWildcard() {
+	p := userPatch{Age: null.New(int32(1))}
+	s.Require().NoError(Apply(&fieldmaskpb.FieldMask{Paths: []string{"*"}}, &p))
+
+	s.Equal(null.New(int32(1)), p.Age)
+	s.True(p.DisplayName.IsNull())
+	s.True(p.Home.IsNull())
+	s.True(p.Address.Zip.IsNull())
+	s.Require().NotNil(p.Billing)
+	s.True(p.Billing.City.IsNull())
+	s.True(p.UpdatedBy.IsNull())
+	s.False(p.Ignored.IsSet())
+}
+
+func (s *MaskSuite) TestApplyPointerStruct() {
+	// A nil pointer named by the mask is allocated to hold the Nulls.
+	var p userPatch
+	s.Require().NoError(Apply(&fieldmaskpb.FieldMask{Paths: []string{"billing"}}, &p))
+	s.Equal(&address{City: null.NewNull[string](), Zip: null.NewNull[string]()}, p.Billing)
+
+	// Fields behind a pointer the mask does not name are reset; a nil
+	// pointer stays nil.
+	p = userPatch{Billing: &address{City: null.New("Lyon"), Zip: null.New("69001")}}
+	s.Require().NoError(Apply(&fieldmaskpb.FieldMask{Paths: []string{"email", "billing.postal_code"}}, &p))
+	s.Equal(&address{Zip: null.New("69001")}, p.Billing)
+	s.True(p.Email.IsNull())
+
+	p = userPatch{}
+	s.Require().NoError(Apply(&fieldmaskpb.FieldMask{Paths: []string{"email"}}, &p))
+	s.Nil(p.Billing)
+}
+
+func (s *MaskSuite) TestApplyNonValuePaths() {
+	type event struct {
+		Name       string `pb:"name"`
+		Title      null.Value[string]
+		UpdateTime time.Time
+		Labels     map[string]string
+	}
+	at := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	e := event{Name: "events/1", UpdateTime: at, Labels: map[string]string{"a": "b"}}
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"name", "title", "update_time", "labels"}}
+	s.Require().NoError(Apply(mask, &e))
+
+	// Only the Value is settled; the others are left to the caller.
+	s.Equal(event{Name: "events/1", Title: null.NewNull[string](), UpdateTime: at, Labels: map[string]string{"a": "b"}}, e)
+
+	m, err := Mask(e)
+	s.Require().NoError(err)
+	s.Equal([]string{"title"}, m.GetPaths())
+}
+
+func (s *MaskSuite) TestRecursiveType() {
+	type node struct {
+		Label null.Value[string]
+		Next  *node
+	}
+	m, err := Mask(node{Label: null.New("a"), Next: &node{Label: null.New("b")}})
+	s.Require().NoError(err)
+	s.Equal([]string{"label"}, m.GetPaths())
+
+	n := node{Next: &node{Label: null.New("b")}}
+	s.Require().NoError(Apply(&fieldmaskpb.FieldMask{Paths: []string{"next"}}, &n))
+	s.Equal(node{Next: &node{Label: null.New("b")}}, n)
+}
+
+func (s *MaskSuite) TestApplyEmptyMask() {
+	p := userPatch{Age: null.New(int32(1))}
+	s.Require().NoError(Apply(nil, &p))
+	s.Require().NoError(Apply(&fieldmaskpb.FieldMask{}, &p))
+	s.Equal(userPatch{Age: null.New(int32(1))}, p)
+}
+
+func (s *MaskSuite) TestApplyErrors() {
+	var p userPatch
+	tests := map[string]struct {
+		mask  []string
+		patch any
+		want  string
+	}{
+		"unknown path":   {[]string{"email", "phone"}, &p, `nullpb: unknown field mask path "phone"`},
+		"inside a value": {[]string{"home.city"}, &p, `nullpb: unknown field mask path "home.city"`},
+		"partial prefix": {[]string{"addr"}, &p, `nullpb: unknown field mask path "addr"`},
+		"skipped field":  {[]string{"ignored"}, &p, `nullpb: unknown field mask path "ignored"`},
+		"inside a leaf":  {[]string{"name.first"}, &p, `nullpb: unknown field mask path "name.first"`},
+		"not a pointer":  {[]string{"email"}, p, "nullpb: Apply requires a non-nil pointer to a struct, got nullpb.userPatch"},
+		"nil pointer":    {[]string{"email"}, (*userPatch)(nil), "requires a non-nil pointer"},
+		"not a struct":   {[]string{"email"}, new(int), "requires a non-nil pointer"},
+	}
+	for name, tt := range tests {
+		s.Run(name, func() {
+			err := Apply(&fieldmaskpb.FieldMask{Paths: tt.mask}, tt.patch)
+			s.ErrorContains(err, tt.want)
+		})
+	}
+	s.Equal(userPatch{}, p)
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Name":        "name",
+		"DisplayName": "display_name",
+		"UserID":      "user_id",
+		"ID":          "id",
+		"HTTPProxy":   "http_proxy",
+		"Address2":    "address2",
+	}
+	for in, want := range tests {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, want, snake.Lower(in))
+		})
+	}
+}
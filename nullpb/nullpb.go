@@ -0,0 +1,135 @@
+// Package nullpb converts between null.Value and the Protocol Buffers
+// well-known types used for partial updates in gRPC APIs.
+//
+// A wrapper message such as google.protobuf.StringValue can only be present
+// or absent, so on its own it carries two of the three states. From maps a
+// present wrapper to a Valid Value and an absent one to Unset, and String,
+// Int64 and the other constructors convert back:
+//
+//	patch := UserPatch{
+//	    Name:  nullpb.From(req.GetName()),
+//	    Email: nullpb.From(req.GetEmail()),
+//	}
+//
+// The third state comes from the request's google.protobuf.FieldMask: a
+// field named in the mask but absent from the message is Null. Apply uses
+// the mask to settle the state of every Value field of a patch struct, and
+// Mask builds the mask for a patch on the client side:
+//
+//	if err := nullpb.Apply(req.GetUpdateMask(), &patch); err != nil {
+//	    return err
+//	}
+//
+// Field mask paths are taken from `pb` struct tags, falling back to the
+// snake_case form of the Go field name (`pb:"-"` skips a field). Untagged
+// embedded structs are flattened, and other struct fields, or pointers to
+// structs, contribute dotted paths such as "address.city". Paths may also
+// name fields that are not Values; Apply accepts them and leaves those
+// fields to the caller.
+package nullpb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bjaus/null"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Wrapper is implemented by the wrapper messages, such as
+// *wrapperspb.StringValue, that hold a single value of type T.
+type Wrapper[T any] interface {
+	proto.Message
+	GetValue() T
+}
+
+// --- From Protocol Buffers ---
+
+// From returns a Valid Value holding the value of w, or an Unset Value if w
+// is nil.
+func From[T any](w Wrapper[T]) null.Value[T] {
+	if w == nil || !w.ProtoReflect().IsValid() {
+		return null.Value[T]{}
+	}
+	return null.New(w.GetValue())
+}
+
+// FromTimestamp returns a Valid Value holding ts as a time.Time, or an
+// Unset Value if ts is nil. It fails if ts is out of range.
+func FromTimestamp(ts *timestamppb.Timestamp) (null.Value[time.Time], error) {
+	if ts == nil {
+		return null.Value[time.Time]{}, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return null.Value[time.Time]{}, err
+	}
+	return null.New(ts.AsTime()), nil
+}
+
+// FromDuration returns a Valid Value holding d as a time.Duration, or an
+// Unset Value if d is nil. It fails if d is invalid or does not fit in a
+// time.Duration.
+func FromDuration(d *durationpb.Duration) (null.Value[time.Duration], error) {
+	if d == nil {
+		return null.Value[time.Duration]{}, nil
+	}
+	if err := d.CheckValid(); err != nil {
+		return null.Value[time.Duration]{}, err
+	}
+	// AsDuration saturates, so a result that does not split back into the
+	// same seconds and nanoseconds has overflowed.
+	td := d.AsDuration()
+	if d.GetSeconds() != int64(td/time.Second) || int64(d.GetNanos()) != int64(td%time.Second) {
+		return null.Value[time.Duration]{}, fmt.Errorf("nullpb: duration %ds overflows time.Duration", d.GetSeconds())
+	}
+	return null.New(td), nil
+}
+
+// --- To Protocol Buffers ---
+
+// The functions below return the wrapper for a Valid Value and nil for a
+// Null or Unset one. A field mask is needed to tell those two apart; see
+// Mask.
+
+// Bool returns v as a BoolValue.
+func Bool(v null.Value[bool]) *wrapperspb.BoolValue { return wrap(v, wrapperspb.Bool) }
+
+// Int32 returns v as an Int32Value.
+func Int32(v null.Value[int32]) *wrapperspb.Int32Value { return wrap(v, wrapperspb.Int32) }
+
+// Int64 returns v as an Int64Value.
+func Int64(v null.Value[int64]) *wrapperspb.Int64Value { return wrap(v, wrapperspb.Int64) }
+
+// UInt32 returns v as a UInt32Value.
+func UInt32(v null.Value[uint32]) *wrapperspb.UInt32Value { return wrap(v, wrapperspb.UInt32) }
+
+// UInt64 returns v as a UInt64Value.
+func UInt64(v null.Value[uint64]) *wrapperspb.UInt64Value { return wrap(v, wrapperspb.UInt64) }
+
+// Float returns v as a FloatValue.
+func Float(v null.Value[float32]) *wrapperspb.FloatValue { return wrap(v, wrapperspb.Float) }
+
+// Double returns v as a DoubleValue.
+func Double(v null.Value[float64]) *wrapperspb.DoubleValue { return wrap(v, wrapperspb.Double) }
+
+// String returns v as a StringValue.
+func String(v null.Value[string]) *wrapperspb.StringValue { return wrap(v, wrapperspb.String) }
+
+// Bytes returns v as a BytesValue.
+func Bytes(v null.Value[[]byte]) *wrapperspb.BytesValue { return wrap(v, wrapperspb.Bytes) }
+
+// Timestamp returns v as a Timestamp.
+func Timestamp(v null.Value[time.Time]) *timestamppb.Timestamp { return wrap(v, timestamppb.New) }
+
+// Duration returns v as a Duration.
+func Duration(v null.Value[time.Duration]) *durationpb.Duration { return wrap(v, durationpb.New) }
+
+func wrap[T, W any](v null.Value[T], newW func(T) *W) *W {
+	if !v.IsValid() {
+		return nil
+	}
+	return newW(v.Get())
+}
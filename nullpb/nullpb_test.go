@@ -0,0 +1,125 @@
+package nullpb
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestFrom(t *testing.T) {
+	tests := map[string]struct {
+		got  any
+		want any
+	}{
+		"bool":        {From(wrapperspb.Bool(true)), null.New(true)},
+		"int32":       {From(wrapperspb.Int32(-1)), null.New(int32(-1))},
+		"int64":       {From(wrapperspb.Int64(math.MaxInt64)), null.New(int64(math.MaxInt64))},
+		"uint32":      {From(wrapperspb.UInt32(1)), null.New(uint32(1))},
+		"uint64":      {From(wrapperspb.UInt64(math.MaxUint64)), null.New(uint64(math.MaxUint64))},
+		"float":       {From(wrapperspb.Float(1.5)), null.New(float32(1.5))},
+		"double":      {From(wrapperspb.Double(2.5)), null.New(2.5)},
+		"string":      {From(wrapperspb.String("a")), null.New("a")},
+		"bytes":       {From(wrapperspb.Bytes([]byte{1})), null.New([]byte{1})},
+		"zero":        {From(wrapperspb.String("")), null.New("")},
+		"nil pointer": {From((*wrapperspb.StringValue)(nil)), null.Value[string]{}},
+		"nil wrapper": {From[string](nil), null.Value[string]{}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
+
+func TestTo(t *testing.T) {
+	tests := map[string]struct {
+		got  proto.Message
+		want proto.Message
+	}{
+		"bool":      {Bool(null.New(true)), wrapperspb.Bool(true)},
+		"int32":     {Int32(null.New(int32(-1))), wrapperspb.Int32(-1)},
+		"int64":     {Int64(null.New(int64(2))), wrapperspb.Int64(2)},
+		"uint32":    {UInt32(null.New(uint32(3))), wrapperspb.UInt32(3)},
+		"uint64":    {UInt64(null.New(uint64(4))), wrapperspb.UInt64(4)},
+		"float":     {Float(null.New(float32(1.5))), wrapperspb.Float(1.5)},
+		"double":    {Double(null.New(2.5)), wrapperspb.Double(2.5)},
+		"string":    {String(null.New("a")), wrapperspb.String("a")},
+		"bytes":     {Bytes(null.New([]byte{1})), wrapperspb.Bytes([]byte{1})},
+		"zero":      {String(null.New("")), wrapperspb.String("")},
+		"timestamp": {Timestamp(null.New(time.Unix(1, 2))), &timestamppb.Timestamp{Seconds: 1, Nanos: 2}},
+		"duration":  {Duration(null.New(-1500 * time.Millisecond)), &durationpb.Duration{Seconds: -1, Nanos: -5e8}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, proto.Equal(tt.want, tt.got), "got %v", tt.got)
+		})
+	}
+}
+
+type ConvertSuite struct {
+	suite.Suite
+}
+
+func TestConvertSuite(t *testing.T) {
+	suite.Run(t, new(ConvertSuite))
+}
+
+func (s *ConvertSuite) TestNullAndUnsetBecomeNil() {
+	s.Nil(String(null.NewNull[string]()))
+	s.Nil(String(null.Value[string]{}))
+	s.Nil(Bytes(null.NewNull[[]byte]()))
+	s.Nil(Timestamp(null.NewNull[time.Time]()))
+	s.Nil(Duration(null.Value[time.Duration]{}))
+}
+
+func (s *ConvertSuite) TestTimestamp() {
+	at := time.Date(2024, 1, 15, 10, 30, 0, 5, time.UTC)
+	v, err := FromTimestamp(Timestamp(null.New(at)))
+	s.Require().NoError(err)
+	s.Equal(null.New(at), v)
+
+	v, err = FromTimestamp(nil)
+	s.Require().NoError(err)
+	s.False(v.IsSet())
+
+	_, err = FromTimestamp(&timestamppb.Timestamp{Nanos: -1})
+	s.Error(err)
+	_, err = FromTimestamp(&timestamppb.Timestamp{Seconds: math.MaxInt64})
+	s.Error(err)
+}
+
+func (s *ConvertSuite) TestDuration() {
+	for _, d := range []time.Duration{0, time.Nanosecond, -90 * time.Second, math.MaxInt64, math.MinInt64} {
+		v, err := FromDuration(Duration(null.New(d)))
+		s.Require().NoError(err, d)
+		s.Equal(null.New(d), v)
+	}
+
+	v, err := FromDuration(nil)
+	s.Require().NoError(err)
+	s.False(v.IsSet())
+
+	_, err = FromDuration(&durationpb.Duration{Seconds: 1, Nanos: -1})
+	s.Error(err)
+
+	_, err = FromDuration(&durationpb.Duration{Seconds: 9223372036, Nanos: 854775808})
+	s.EqualError(err, "nullpb: duration 9223372036s overflows time.Duration")
+
+	_, err = FromDuration(&durationpb.Duration{Seconds: -315576000000})
+	s.ErrorContains(err, "overflows time.Duration")
+}
+
+func TestFromRoundTrip(t *testing.T) {
+	v := null.New("x")
+	require.Equal(t, v, From(String(v)))
+	require.Equal(t, null.Value[string]{}, From(String(null.NewNull[string]())))
+}
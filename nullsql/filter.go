@@ -0,0 +1,243 @@
+package nullsql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/bjaus/null"
+)
+
+// Cond is a WHERE-clause condition built by Filter, And or Or.
+type Cond interface {
+	// write renders the condition. nested reports whether it is an operand
+	// of another group and needs parentheses when it has several parts.
+	write(w *writer, nested bool)
+	empty() bool
+}
+
+// Where renders c as a WHERE clause for dialect d, returning an empty string
+// and no arguments when c has no conditions:
+//
+//	WHERE status IS NULL AND age >= $1
+func Where(d Dialect, c Cond) (string, []any) {
+	return WhereFrom(d, 1, c)
+}
+
+// WhereFrom is like Where but numbers placeholders from start, so that the
+// clause can follow start-1 arguments already bound in the statement:
+//
+//	where, args := nullsql.WhereFrom(nullsql.Dollar, len(setArgs)+1, cond)
+func WhereFrom(d Dialect, start int, c Cond) (string, []any) {
+	if c == nil || c.empty() {
+		return "", nil
+	}
+	w := &writer{d: d, offset: start - 1}
+	w.b.WriteString("WHERE ")
+	c.write(w, false)
+	return w.b.String(), w.args
+}
+
+type writer struct {
+	d      Dialect
+	b      strings.Builder
+	args   []any
+	offset int // arguments bound before the clause
+}
+
+func (w *writer) bind(arg any) {
+	w.args = append(w.args, arg)
+	w.b.WriteString(w.d.Placeholder(w.offset + len(w.args)))
+}
+
+// --- Groups ---
+
+type group struct {
+	sep   string
+	conds []Cond
+}
+
+// And joins conditions with AND. Empty conditions are dropped.
+func And(conds ...Cond) Cond {
+	return group{sep: " AND ", conds: conds}
+}
+
+// Or joins conditions with OR. Empty conditions are dropped.
+func Or(conds ...Cond) Cond {
+	return group{sep: " OR ", conds: conds}
+}
+
+func (g group) live() []Cond {
+	var live []Cond
+	for _, c := range g.conds {
+		if c != nil && !c.empty() {
+			live = append(live, c)
+		}
+	}
+	return live
+}
+
+func (g group) empty() bool {
+	return len(g.live()) == 0
+}
+
+func (g group) write(w *writer, nested bool) {
+	live := g.live()
+	if len(live) == 1 {
+		live[0].write(w, nested)
+		return
+	}
+	if nested {
+		w.b.WriteByte('(')
+	}
+	for i, c := range live {
+		if i > 0 {
+			w.b.WriteString(g.sep)
+		}
+		c.write(w, true)
+	}
+	if nested {
+		w.b.WriteByte(')')
+	}
+}
+
+// --- Predicates ---
+
+// Operators accepted in `op` tags. The default is "=".
+var operators = map[string]bool{
+	"=": true, "<>": true, "!=": true,
+	"<": true, "<=": true, ">": true, ">=": true,
+	"LIKE": true, "NOT LIKE": true,
+	"IN": true, "NOT IN": true,
+}
+
+// negated operators match Null fields with IS NOT NULL instead of IS NULL.
+var negated = map[string]bool{"<>": true, "!=": true, "NOT LIKE": true, "NOT IN": true}
+
+type pred struct {
+	col  string
+	op   string
+	null bool
+	args []any
+}
+
+func (p pred) empty() bool {
+	return false
+}
+
+func (p pred) write(w *writer, _ bool) {
+	list := p.op == "IN" || p.op == "NOT IN"
+	if list && !p.null && len(p.args) == 0 {
+		// An empty list matches nothing (IN) or everything (NOT IN).
+		if p.op == "IN" {
+			w.b.WriteString("1 = 0")
+		} else {
+			w.b.WriteString("1 = 1")
+		}
+		return
+	}
+
+	w.b.WriteString(p.col)
+	switch {
+	case p.null && negated[p.op]:
+		w.b.WriteString(" IS NOT NULL")
+	case p.null:
+		w.b.WriteString(" IS NULL")
+	case list:
+		w.b.WriteString(" " + p.op + " (")
+		for i, arg := range p.args {
+			if i > 0 {
+				w.b.WriteString(", ")
+			}
+			w.bind(arg)
+		}
+		w.b.WriteByte(')')
+	default:
+		w.b.WriteString(" " + p.op + " ")
+		w.bind(p.args[0])
+	}
+}
+
+// Filter builds an AND of conditions from a filter struct of Value fields.
+// Each field contributes according to its state:
+//   - Unset adds no condition.
+//   - Null adds "col IS NULL" ("IS NOT NULL" for <>, !=, NOT LIKE, NOT IN).
+//   - Valid adds "col <op> ?" with the field's driver value.
+//
+// The operator comes from the field's `op` tag and defaults to "=". It may
+// be =, <>, !=, <, <=, >, >=, LIKE, NOT LIKE, IN or NOT IN; IN and NOT IN
+// require a slice and expand to one placeholder per element. Every field
+// must be a Value; skip others with `db:"-"`. Several fields may target the
+// same column, e.g. for ranges:
+//
+//	type UserFilter struct {
+//	    Status  null.Value[string]   `db:"status"`
+//	    MinAge  null.Value[int]      `db:"age" op:">="`
+//	    Roles   null.Value[[]string] `db:"role" op:"IN"`
+//	}
+func Filter(filter any) (Cond, error) {
+	rv := reflect.ValueOf(filter)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return group{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("nullsql: Filter expects a struct, got %T", filter)
+	}
+
+	var conds []Cond
+	for _, f := range fieldsOf(rv.Type()) {
+		if !f.value {
+			return nil, fmt.Errorf("nullsql: column %s: filter field of type %s is not a null.Value",
+				f.name, rv.Type().FieldByIndex(f.index).Type)
+		}
+		op := strings.ToUpper(f.op)
+		if op == "" {
+			op = "="
+		}
+		if !operators[op] {
+			return nil, fmt.Errorf("nullsql: column %s: unsupported operator %q", f.name, f.op)
+		}
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+
+		p := pred{col: f.name, op: op}
+		switch fv.Interface().(interface{ State() null.State }).State() {
+		case null.Unset:
+			continue
+		case null.Null:
+			p.null = true
+		default:
+			arg, err := argOf(fv)
+			if err != nil {
+				return nil, fmt.Errorf("nullsql: column %s: %w", f.name, err)
+			}
+			if op == "IN" || op == "NOT IN" {
+				if p.args, err = expand(arg); err != nil {
+					return nil, fmt.Errorf("nullsql: column %s: %w", f.name, err)
+				}
+			} else {
+				p.args = []any{arg}
+			}
+		}
+		conds = append(conds, p)
+	}
+	return group{sep: " AND ", conds: conds}, nil
+}
+
+// expand returns the elements of the slice or array arg.
+func expand(arg any) ([]any, error) {
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("IN requires a slice, got %T", arg)
+	}
+	args := make([]any, v.Len())
+	for i := range args {
+		args[i] = v.Index(i).Interface()
+	}
+	return args, nil
+}
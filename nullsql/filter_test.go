@@ -0,0 +1,160 @@
+package nullsql
+
+import (
+	"testing"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/suite"
+)
+
+type userFilter struct {
+	Status null.Value[string]   `db:"status"`
+	MinAge null.Value[int]      `db:"age" op:">="`
+	MaxAge null.Value[int]      `db:"age" op:"<="`
+	Name   null.Value[string]   `db:"name" op:"like"`
+	Roles  null.Value[[]string] `db:"role" op:"IN"`
+	Owner  null.Value[string]   `db:"owner" op:"<>"`
+	Page   int                  `db:"-"`
+}
+
+type FilterSuite struct {
+	suite.Suite
+}
+
+func TestFilterSuite(t *testing.T) {
+	suite.Run(t, new(FilterSuite))
+}
+
+func (s *FilterSuite) where(d Dialect, filter any) (string, []any) {
+	c, err := Filter(filter)
+	s.Require().NoError(err)
+	return Where(d, c)
+}
+
+func (s *FilterSuite) TestStates() {
+	tests := map[string]struct {
+		filter userFilter
+		want   string
+		args   []any
+	}{
+		"unset":       {userFilter{}, "", nil},
+		"null":        {userFilter{Status: null.NewNull[string]()}, "WHERE status IS NULL", nil},
+		"valid":       {userFilter{Status: null.New("active")}, "WHERE status = $1", []any{"active"}},
+		"negated nul": {userFilter{Owner: null.NewNull[string]()}, "WHERE owner IS NOT NULL", nil},
+		"range": {
+			userFilter{MinAge: null.New(18), MaxAge: null.New(65)},
+			"WHERE age >= $1 AND age <= $2",
+			[]any{int64(18), int64(65)},
+		},
+		"like": {userFilter{Name: null.New("Al%")}, "WHERE name LIKE $1", []any{"Al%"}},
+		"in": {
+			userFilter{Roles: null.New([]string{"admin", "owner"})},
+			"WHERE role IN ($1, $2)",
+			[]any{"admin", "owner"},
+		},
+		"empty in": {userFilter{Roles: null.New([]string{}), Status: null.New("a")}, "WHERE status = $1 AND 1 = 0", []any{"a"}},
+	}
+	for name, tt := range tests {
+		s.Run(name, func() {
+			where, args := s.where(Dollar, tt.filter)
+			s.Equal(tt.want, where)
+			s.Equal(tt.args, args)
+		})
+	}
+}
+
+func (s *FilterSuite) TestDialects() {
+	f := &userFilter{Status: null.New("active"), Roles: null.New([]string{"a", "b"})}
+
+	where, _ := s.where(Question, f)
+	s.Equal("WHERE status = ? AND role IN (?, ?)", where)
+
+	where, _ = s.where(AtP, f)
+	s.Equal("WHERE status = @p1 AND role IN (@p2, @p3)", where)
+}
+
+func (s *FilterSuite) TestGroups() {
+	active, err := Filter(userFilter{Status: null.New("active"), MinAge: null.New(18)})
+	s.Require().NoError(err)
+	unowned, err := Filter(userFilter{Owner: null.NewNull[string]()})
+	s.Require().NoError(err)
+	empty, err := Filter(userFilter{})
+	s.Require().NoError(err)
+
+	where, args := Where(Dollar, And(Or(active, unowned), empty))
+	s.Equal("WHERE (status = $1 AND age >= $2) OR owner IS NOT NULL", where)
+	s.Equal([]any{"active", int64(18)}, args)
+
+	where, _ = Where(Dollar, Or(empty, unowned))
+	s.Equal("WHERE owner IS NOT NULL", where)
+
+	where, args = Where(Dollar, And(empty, Or()))
+	s.Empty(where)
+	s.Nil(args)
+}
+
+func (s *FilterSuite) TestNilFilter() {
+	where, args := s.where(Dollar, (*userFilter)(nil))
+	s.Empty(where)
+	s.Nil(args)
+
+	where, _ = Where(Dollar, nil)
+	s.Empty(where)
+}
+
+func (s *FilterSuite) TestErrors() {
+	type badOp struct {
+		Age null.Value[int] `db:"age" op:"~"`
+	}
+	_, err := Filter(badOp{})
+	s.Error(err)
+
+	type badIn struct {
+		Age null.Value[int] `db:"age" op:"IN"`
+	}
+	_, err = Filter(badIn{Age: null.New(1)})
+	s.Error(err)
+
+	type plainField struct {
+		Status   null.Value[string] `db:"status"`
+		Archived bool               `db:"archived"`
+	}
+	_, err = Filter(plainField{})
+	s.EqualError(err, "nullsql: column archived: filter field of type bool is not a null.Value")
+
+	_, err = Filter("filter")
+	s.Error(err)
+}
+
+func (s *FilterSuite) TestWhereFrom() {
+	c, err := Filter(userFilter{Status: null.New("active"), Roles: null.New([]string{"a"})})
+	s.Require().NoError(err)
+
+	where, args := WhereFrom(Dollar, 3, c)
+	s.Equal("WHERE status = $3 AND role IN ($4)", where)
+	s.Equal([]any{"active", "a"}, args)
+
+	where, _ = WhereFrom(AtP, 2, c)
+	s.Equal("WHERE status = @p2 AND role IN (@p3)", where)
+}
+
+func (s *FilterSuite) TestUpdateWhere() {
+	type patch struct {
+		Name  null.Value[string] `db:"name"`
+		Email null.Value[string] `db:"email"`
+	}
+	c, err := Filter(userFilter{Owner: null.NewNull[string](), MinAge: null.New(18)})
+	s.Require().NoError(err)
+
+	query, args, err := UpdateWhere(Dollar, "users", c, patch{Name: null.New("Alice"), Email: null.NewNull[string]()})
+	s.Require().NoError(err)
+	s.Equal("UPDATE users SET name = $1, email = NULL WHERE age >= $2 AND owner IS NOT NULL", query)
+	s.Equal([]any{"Alice", int64(18)}, args)
+
+	empty, err := Filter(userFilter{})
+	s.Require().NoError(err)
+	_, _, err = UpdateWhere(Dollar, "users", empty, patch{Name: null.New("Alice")})
+	s.ErrorIs(err, ErrNoKey)
+	_, _, err = UpdateWhere(Dollar, "users", c, patch{})
+	s.ErrorIs(err, ErrNoColumns)
+}
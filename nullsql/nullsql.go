@@ -0,0 +1,310 @@
+// Package nullsql builds SQL statements from structs of null.Value fields.
+//
+// Column names come from `db` struct tags (or the lowercased field name when
+// untagged; `db:"-"` skips a field). Unset fields are left out of the
+// statement entirely, Null fields are written as a NULL literal and Valid
+// fields become placeholders bound to their driver.Valuer value.
+//
+// Update and Insert build statements from patch structs; Filter, And, Or
+// and Where build WHERE clauses from filter structs, where Unset means no
+// condition and Null means IS NULL. UpdateWhere combines the two.
+//
+// Table and column names are inserted verbatim and must not come from
+// untrusted input.
+package nullsql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+)
+
+var (
+	// ErrNoColumns is returned when a patch has no set fields, so there is
+	// nothing to update or insert.
+	ErrNoColumns = errors.New("nullsql: no columns set")
+
+	// ErrNoKey is returned by Update and UpdateWhere when the key or
+	// condition is empty, which would otherwise update every row in the
+	// table.
+	ErrNoKey = errors.New("nullsql: empty key")
+)
+
+// --- Dialects ---
+
+// Dialect selects the placeholder style of generated statements.
+type Dialect uint8
+
+const (
+	// Dollar numbers placeholders as $1, $2, ... (PostgreSQL).
+	Dollar Dialect = iota
+
+	// Question uses ? for every placeholder (MySQL, SQLite).
+	Question
+
+	// AtP numbers placeholders as @p1, @p2, ... (SQL Server).
+	AtP
+)
+
+// Placeholder returns the n-th (1-based) placeholder.
+func (d Dialect) Placeholder(n int) string {
+	switch d {
+	case Question:
+		return "?"
+	case AtP:
+		return "@p" + strconv.Itoa(n)
+	default:
+		return "$" + strconv.Itoa(n)
+	}
+}
+
+// --- Statements ---
+
+// Key identifies the rows an UPDATE applies to, as column = value pairs
+// joined with AND. A nil value matches with IS NULL. Columns are emitted in
+// sorted order so statements are deterministic.
+type Key map[string]any
+
+// Update builds an UPDATE statement that sets the set fields of patch on the
+// rows matching key:
+//
+//	UPDATE users SET name = $1, email = NULL WHERE id = $2
+//
+// It returns ErrNoColumns when every field is Unset and ErrNoKey when key
+// is empty.
+func Update(d Dialect, table string, key Key, patch any) (string, []any, error) {
+	if len(key) == 0 {
+		return "", nil, ErrNoKey
+	}
+	b, args, err := updateSet(d, table, patch)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b.WriteString(" WHERE ")
+	for i, col := range slices.Sorted(maps.Keys(key)) {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		b.WriteString(col)
+		if key[col] == nil {
+			b.WriteString(" IS NULL")
+			continue
+		}
+		args = append(args, key[col])
+		b.WriteString(" = ")
+		b.WriteString(d.Placeholder(len(args)))
+	}
+	return b.String(), args, nil
+}
+
+// UpdateWhere is like Update but applies to the rows matching cond, a
+// condition built by Filter, And or Or. Its placeholders are numbered after
+// those of the SET clause:
+//
+//	UPDATE users SET name = $1 WHERE status IS NULL AND age >= $2
+//
+// It returns ErrNoColumns when every field is Unset and ErrNoKey when cond
+// has no conditions.
+func UpdateWhere(d Dialect, table string, cond Cond, patch any) (string, []any, error) {
+	if cond == nil || cond.empty() {
+		return "", nil, ErrNoKey
+	}
+	b, args, err := updateSet(d, table, patch)
+	if err != nil {
+		return "", nil, err
+	}
+	where, whereArgs := WhereFrom(d, len(args)+1, cond)
+	b.WriteByte(' ')
+	b.WriteString(where)
+	return b.String(), append(args, whereArgs...), nil
+}
+
+// updateSet writes the UPDATE statement up to the end of its SET clause.
+func updateSet(d Dialect, table string, patch any) (*strings.Builder, []any, error) {
+	cols, err := columnsOf(patch)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cols) == 0 {
+		return nil, nil, ErrNoColumns
+	}
+
+	var b strings.Builder
+	var args []any
+	b.WriteString("UPDATE ")
+	b.WriteString(table)
+	b.WriteString(" SET ")
+	for i, c := range cols {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(c.name)
+		b.WriteString(" = ")
+		if c.null {
+			b.WriteString("NULL")
+			continue
+		}
+		args = append(args, c.arg)
+		b.WriteString(d.Placeholder(len(args)))
+	}
+	return &b, args, nil
+}
+
+// Insert builds an INSERT statement for the set fields of row. Unset fields
+// are omitted so the database applies its column defaults:
+//
+//	INSERT INTO users (name, email) VALUES ($1, NULL)
+//
+// It returns ErrNoColumns when every field is Unset.
+func Insert(d Dialect, table string, row any) (string, []any, error) {
+	cols, err := columnsOf(row)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(cols) == 0 {
+		return "", nil, ErrNoColumns
+	}
+
+	var names, values strings.Builder
+	var args []any
+	for i, c := range cols {
+		if i > 0 {
+			names.WriteString(", ")
+			values.WriteString(", ")
+		}
+		names.WriteString(c.name)
+		if c.null {
+			values.WriteString("NULL")
+			continue
+		}
+		args = append(args, c.arg)
+		values.WriteString(d.Placeholder(len(args)))
+	}
+	return "INSERT INTO " + table + " (" + names.String() + ") VALUES (" + values.String() + ")", args, nil
+}
+
+// --- Columns ---
+
+// column is a set field of a patch struct.
+type column struct {
+	name string
+	null bool
+	arg  any
+}
+
+// columnsOf returns the set columns of the struct (or pointer to struct) v
+// in field order.
+func columnsOf(v any) ([]column, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("nullsql: expected a struct, got %T", v)
+	}
+
+	var cols []column
+	for _, f := range fieldsOf(rv.Type()) {
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+		c := column{name: f.name}
+		if f.value {
+			switch fv.Interface().(interface{ State() null.State }).State() {
+			case null.Unset:
+				continue
+			case null.Null:
+				c.null = true
+				cols = append(cols, c)
+				continue
+			}
+		}
+		arg, err := argOf(fv)
+		if err != nil {
+			return nil, fmt.Errorf("nullsql: column %s: %w", f.name, err)
+		}
+		if arg == nil {
+			c.null = true
+		}
+		c.arg = arg
+		cols = append(cols, c)
+	}
+	return cols, nil
+}
+
+// argOf returns the driver value for fv, resolving driver.Valuer so that
+// Values are bound as their inner value. Nil pointers are NULL.
+func argOf(fv reflect.Value) (any, error) {
+	if fv.Kind() == reflect.Pointer && fv.IsNil() {
+		return nil, nil
+	}
+	if valuer, ok := fv.Interface().(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return fv.Interface(), nil
+}
+
+var valuer = reflect.TypeFor[driver.Valuer]()
+
+type field struct {
+	name  string
+	index []int
+	value bool   // field is a null.Value
+	op    string // `op` tag, used by Filter
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// fieldsOf lists the columns of struct type t, flattening untagged embedded
+// structs.
+func fieldsOf(t reflect.Type) []field {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.([]field)
+	}
+	var fields []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := sf.Type
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct &&
+			!bridge.IsValue(ft) && !ft.Implements(valuer) {
+			for _, f := range fieldsOf(ft) {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		fields = append(fields, field{
+			name:  name,
+			index: []int{i},
+			value: bridge.IsValue(ft),
+			op:    sf.Tag.Get("op"),
+		})
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,147 @@
+package nullsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Audit struct {
+	UpdatedBy null.Value[string] `db:"updated_by"`
+}
+
+type userPatch struct {
+	Name     null.Value[string]    `db:"name"`
+	Email    null.Value[string]    `db:"email"`
+	Age      null.Value[int]       `db:"age"`
+	Birthday null.Value[time.Time] `db:"birthday"`
+	Nickname null.Value[string]
+	Ignored  null.Value[string] `db:"-"`
+	Audit
+}
+
+func TestDialect_Placeholder(t *testing.T) {
+	tests := map[string]struct {
+		d    Dialect
+		want string
+	}{
+		"dollar":   {Dollar, "$3"},
+		"question": {Question, "?"},
+		"atp":      {AtP, "@p3"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.d.Placeholder(3))
+		})
+	}
+}
+
+// --- Update Tests ---
+
+type UpdateSuite struct {
+	suite.Suite
+}
+
+func TestUpdateSuite(t *testing.T) {
+	suite.Run(t, new(UpdateSuite))
+}
+
+func (s *UpdateSuite) TestSkipsUnset() {
+	p := userPatch{Name: null.New("Alice"), Email: null.NewNull[string](), Age: null.New(30)}
+	query, args, err := Update(Dollar, "users", Key{"id": 7}, p)
+	s.Require().NoError(err)
+	s.Equal("UPDATE users SET name = $1, email = NULL, age = $2 WHERE id = $3", query)
+	s.Equal([]any{"Alice", int64(30), 7}, args)
+}
+
+func (s *UpdateSuite) TestDialects() {
+	p := &userPatch{Name: null.New("Alice"), Nickname: null.New("ally")}
+	key := Key{"tenant_id": 1, "id": 7}
+
+	tests := map[string]struct {
+		d    Dialect
+		want string
+	}{
+		"dollar":   {Dollar, "UPDATE users SET name = $1, nickname = $2 WHERE id = $3 AND tenant_id = $4"},
+		"question": {Question, "UPDATE users SET name = ?, nickname = ? WHERE id = ? AND tenant_id = ?"},
+		"atp":      {AtP, "UPDATE users SET name = @p1, nickname = @p2 WHERE id = @p3 AND tenant_id = @p4"},
+	}
+	for name, tt := range tests {
+		s.Run(name, func() {
+			query, args, err := Update(tt.d, "users", key, p)
+			s.Require().NoError(err)
+			s.Equal(tt.want, query)
+			s.Equal([]any{"Alice", "ally", 7, 1}, args)
+		})
+	}
+}
+
+func (s *UpdateSuite) TestEmbeddedAndNullKey() {
+	p := userPatch{Audit: Audit{UpdatedBy: null.New("admin")}}
+	query, args, err := Update(Question, "users", Key{"deleted_at": nil, "id": 7}, p)
+	s.Require().NoError(err)
+	s.Equal("UPDATE users SET updated_by = ? WHERE deleted_at IS NULL AND id = ?", query)
+	s.Equal([]any{"admin", 7}, args)
+}
+
+func (s *UpdateSuite) TestTimeArg() {
+	at := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+	_, args, err := Update(Dollar, "users", Key{"id": 1}, userPatch{Birthday: null.New(at)})
+	s.Require().NoError(err)
+	s.Equal([]any{at, 1}, args)
+}
+
+func (s *UpdateSuite) TestNothingSet() {
+	_, _, err := Update(Dollar, "users", Key{"id": 7}, userPatch{Ignored: null.New("x")})
+	s.ErrorIs(err, ErrNoColumns)
+
+	_, _, err = Update(Dollar, "users", Key{"id": 7}, (*userPatch)(nil))
+	s.ErrorIs(err, ErrNoColumns)
+}
+
+func (s *UpdateSuite) TestEmptyKey() {
+	_, _, err := Update(Dollar, "users", nil, userPatch{Name: null.New("Alice")})
+	s.ErrorIs(err, ErrNoKey)
+}
+
+func (s *UpdateSuite) TestNotAStruct() {
+	_, _, err := Update(Dollar, "users", Key{"id": 7}, 42)
+	s.Error(err)
+}
+
+func (s *UpdateSuite) TestPlainFields() {
+	type patch struct {
+		Name    string  `db:"name"`
+		Comment *string `db:"comment"`
+	}
+	query, args, err := Update(Dollar, "notes", Key{"id": 1}, patch{Name: "n"})
+	s.Require().NoError(err)
+	s.Equal("UPDATE notes SET name = $1, comment = NULL WHERE id = $2", query)
+	s.Equal([]any{"n", 1}, args)
+}
+
+// --- Insert Tests ---
+
+type InsertSuite struct {
+	suite.Suite
+}
+
+func TestInsertSuite(t *testing.T) {
+	suite.Run(t, new(InsertSuite))
+}
+
+func (s *InsertSuite) TestOmitsUnset() {
+	p := userPatch{Name: null.New("Alice"), Email: null.NewNull[string](), Age: null.New(30)}
+	query, args, err := Insert(AtP, "users", p)
+	s.Require().NoError(err)
+	s.Equal("INSERT INTO users (name, email, age) VALUES (@p1, NULL, @p2)", query)
+	s.Equal([]any{"Alice", int64(30)}, args)
+}
+
+func (s *InsertSuite) TestNothingSet() {
+	_, _, err := Insert(Dollar, "users", userPatch{})
+	s.ErrorIs(err, ErrNoColumns)
+}
@@ -0,0 +1,271 @@
+// Package nullyaml adds YAML (gopkg.in/yaml.v3) support to null.Value.
+//
+// Value[T] embeds null.Value[T] and maps the three states onto YAML: an
+// absent key is Unset, ~ or null is Null and anything else is Valid. Tag a
+// field with omitempty to drop only the Unset fields when marshaling:
+//
+//	type Config struct {
+//	    Name    nullyaml.Value[string] `yaml:"name,omitempty"`
+//	    Timeout nullyaml.Value[int]    `yaml:"timeout,omitempty"`
+//	}
+//
+// yaml.v3 does not call unmarshalers for null nodes, so yaml.Unmarshal
+// leaves a field that is null in the document Unset. Use Unmarshal or
+// Decode from this package to get Null instead.
+package nullyaml
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/bjaus/null"
+	"github.com/bjaus/null/internal/bridge"
+	"gopkg.in/yaml.v3"
+)
+
+// Value wraps null.Value[T] and adds YAML marshaling support.
+type Value[T any] struct {
+	null.Value[T]
+}
+
+// --- Constructors ---
+
+// New creates a valid Value containing v.
+func New[T any](v T) Value[T] {
+	return Value[T]{null.New(v)}
+}
+
+// NewNull creates a Value that is explicitly null.
+func NewNull[T any]() Value[T] {
+	return Value[T]{null.NewNull[T]()}
+}
+
+// NewPtr creates a Value from a pointer.
+func NewPtr[T any](p *T) Value[T] {
+	return Value[T]{null.NewPtr(p)}
+}
+
+// From wraps an existing null.Value.
+func From[T any](v null.Value[T]) Value[T] {
+	return Value[T]{v}
+}
+
+// --- YAML ---
+
+// MarshalYAML implements yaml.Marshaler. Valid values are marshaled as T;
+// Null and Unset values as null. IsZero reports true only for Unset values,
+// so omitempty omits exactly those.
+func (v Value[T]) MarshalYAML() (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	return v.Get(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. The node is decoded into T
+// with Decode, so Values nested in T get their Null state too.
+func (v *Value[T]) UnmarshalYAML(node *yaml.Node) error {
+	if isNull(node) {
+		*v = NewNull[T]()
+		return nil
+	}
+	var x T
+	if err := Decode(node, &x); err != nil {
+		return err
+	}
+	*v = New(x)
+	return nil
+}
+
+// Unmarshal is like yaml.Unmarshal but sets Values whose node is null to
+// Null rather than leaving them Unset.
+func Unmarshal(data []byte, out any) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	if node.Kind == 0 {
+		return nil
+	}
+	return Decode(&node, out)
+}
+
+// Decode is like node.Decode but sets Values whose node is null to Null
+// rather than leaving them Unset. It also handles plain null.Value fields,
+// which yaml.v3 decodes from scalars through their UnmarshalText method.
+func Decode(node *yaml.Node, out any) error {
+	if err := node.Decode(out); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil
+	}
+	return markNulls(node, rv.Elem())
+}
+
+func isNull(n *yaml.Node) bool {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null"
+}
+
+func nilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+// decodeValues decodes the sequence n into v, a slice or array of Values,
+// element by element. yaml.v3 drops null elements, which would shift the
+// others, so the sequence is decoded again with nulls kept as Null.
+func decodeValues(n *yaml.Node, v reflect.Value) error {
+	if v.Kind() == reflect.Slice {
+		if !v.CanSet() {
+			return nil
+		}
+		v.Set(reflect.MakeSlice(v.Type(), len(n.Content), len(n.Content)))
+	}
+	for i, c := range n.Content {
+		if i >= v.Len() {
+			break
+		}
+		e := v.Index(i)
+		e.SetZero()
+		if isNull(c) {
+			bridge.Set(e, reflect.Value{})
+			continue
+		}
+		if err := Decode(c, e.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markNulls walks n alongside the decoded value v and sets the Values at
+// null nodes to Null.
+func markNulls(n *yaml.Node, v reflect.Value) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) != 1 {
+			return nil
+		}
+		return markNulls(n.Content[0], v)
+	case yaml.AliasNode:
+		return markNulls(n.Alias, v)
+	}
+
+	if bridge.IsValue(v.Type()) {
+		if isNull(n) && v.CanAddr() {
+			bridge.Set(v, reflect.Value{})
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return markNulls(n, v.Elem())
+		}
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return nil
+		}
+		fields := fieldsOf(v.Type())
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			index, ok := fields[n.Content[i].Value]
+			if !ok {
+				continue
+			}
+			fv, err := v.FieldByIndexErr(index)
+			if err != nil {
+				continue
+			}
+			if err := markNulls(n.Content[i+1], fv); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return nil
+		}
+		if bridge.IsValue(v.Type().Elem()) {
+			return decodeValues(n, v)
+		}
+		// yaml.v3 drops null elements it cannot store, so skip them to keep
+		// nodes and elements aligned.
+		j := 0
+		for _, c := range n.Content {
+			if isNull(c) && !nilable(v.Type().Elem()) {
+				continue
+			}
+			if j < v.Len() {
+				if err := markNulls(c, v.Index(j)); err != nil {
+					return err
+				}
+			}
+			j++
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode || v.IsNil() {
+			return nil
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := reflect.New(v.Type().Key())
+			if n.Content[i].Decode(k.Interface()) != nil {
+				continue
+			}
+			e := v.MapIndex(k.Elem())
+			if !e.IsValid() {
+				continue
+			}
+			// Map elements are not addressable; fix a copy and store it back.
+			cp := reflect.New(e.Type()).Elem()
+			cp.Set(e)
+			if err := markNulls(n.Content[i+1], cp); err != nil {
+				return err
+			}
+			v.SetMapIndex(k.Elem(), cp)
+		}
+	}
+	return nil
+}
+
+// fieldsOf maps the YAML keys of struct type t to field indexes, following
+// the naming rules of yaml.v3: the `yaml` tag name, or the lowercased field
+// name, with ,inline structs flattened.
+func fieldsOf(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int)
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		tag := sf.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+opts+",", ",inline,") {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, index := range fieldsOf(ft) {
+					fields[k] = append([]int{i}, index...)
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		fields[name] = []int{i}
+	}
+	return fields
+}
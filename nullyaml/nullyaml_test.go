@@ -0,0 +1,192 @@
+package nullyaml
+
+import (
+	"testing"
+
+	"github.com/bjaus/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+type address struct {
+	City Value[string] `yaml:"city"`
+	Zip  Value[string] `yaml:"zip,omitempty"`
+}
+
+type Meta struct {
+	Owner Value[string] `yaml:"owner"`
+}
+
+type config struct {
+	Name     Value[string]         `yaml:"name,omitempty"`
+	Port     Value[int]            `yaml:"port,omitempty"`
+	Tags     Value[[]string]       `yaml:"tags,omitempty"`
+	Address  Value[address]        `yaml:"address,omitempty"`
+	Billing  *address              `yaml:"billing,omitempty"`
+	Replicas []address             `yaml:"replicas,omitempty"`
+	Limits   map[string]Value[int] `yaml:"limits,omitempty"`
+	Plain    null.Value[string]    `yaml:"plain,omitempty"`
+	Untagged Value[bool]           `yaml:",omitempty"`
+	Skipped  Value[string]         `yaml:"-"`
+	Meta     `yaml:",inline"`
+}
+
+func TestUnmarshal_States(t *testing.T) {
+	tests := map[string]struct {
+		doc  string
+		want Value[string]
+	}{
+		"absent":       {"port: 1", Value[string]{}},
+		"null":         {"name: null", NewNull[string]()},
+		"tilde":        {"name: ~", NewNull[string]()},
+		"empty":        {"name:", NewNull[string]()},
+		"value":        {"name: Alice", New("Alice")},
+		"empty string": {`name: ""`, New("")},
+		"quoted null":  {`name: "null"`, New("null")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var c config
+			require.NoError(t, Unmarshal([]byte(tt.doc), &c))
+			assert.Equal(t, tt.want, c.Name)
+		})
+	}
+}
+
+type YAMLSuite struct {
+	suite.Suite
+}
+
+func TestYAMLSuite(t *testing.T) {
+	suite.Run(t, new(YAMLSuite))
+}
+
+func (s *YAMLSuite) TestPlainYAMLUnmarshal() {
+	var c config
+	s.Require().NoError(yaml.Unmarshal([]byte("name: null\nport: 8080"), &c))
+	s.False(c.Name.IsSet(), "yaml.v3 skips unmarshalers for null nodes")
+	s.Equal(New(8080), c.Port)
+}
+
+func (s *YAMLSuite) TestNested() {
+	doc := `
+address:
+  city: ~
+  zip: "75001"
+billing:
+  city: null
+replicas:
+  - city: Paris
+  - city: null
+limits:
+  cpu: 2
+  mem: null
+owner: null
+untagged: true
+`
+	var c config
+	s.Require().NoError(Unmarshal([]byte(doc), &c))
+
+	s.Require().True(c.Address.IsValid())
+	s.True(c.Address.Get().City.IsNull())
+	s.Equal(New("75001"), c.Address.Get().Zip)
+
+	s.Require().NotNil(c.Billing)
+	s.True(c.Billing.City.IsNull())
+
+	s.Require().Len(c.Replicas, 2)
+	s.Equal(New("Paris"), c.Replicas[0].City)
+	s.True(c.Replicas[1].City.IsNull())
+
+	s.Equal(map[string]Value[int]{"cpu": New(2), "mem": NewNull[int]()}, c.Limits)
+	s.True(c.Owner.IsNull())
+	s.Equal(New(true), c.Untagged)
+}
+
+func (s *YAMLSuite) TestNestedInPlainYAML() {
+	var c config
+	s.Require().NoError(yaml.Unmarshal([]byte("address:\n  city: null\n"), &c))
+	s.True(c.Address.Get().City.IsNull(), "UnmarshalYAML decodes T with Decode")
+}
+
+func (s *YAMLSuite) TestSequenceAndAlias() {
+	doc := `
+base: &base null
+values: [1, null, *base]
+`
+	var out struct {
+		Values []Value[int] `yaml:"values"`
+	}
+	s.Require().NoError(Unmarshal([]byte(doc), &out))
+	s.Equal([]Value[int]{New(1), NewNull[int](), NewNull[int]()}, out.Values)
+}
+
+func (s *YAMLSuite) TestDroppedNullElements() {
+	var c config
+	s.Require().NoError(Unmarshal([]byte("replicas: [{city: a}, null, {city: null}]"), &c))
+	s.Require().Len(c.Replicas, 2)
+	s.Equal(New("a"), c.Replicas[0].City)
+	s.True(c.Replicas[1].City.IsNull())
+}
+
+func (s *YAMLSuite) TestPlainNullValue() {
+	var c config
+	s.Require().NoError(Unmarshal([]byte("plain: ~"), &c))
+	s.True(c.Plain.IsNull())
+
+	s.Require().NoError(Unmarshal([]byte("plain: x"), &c))
+	s.Equal(null.New("x"), c.Plain)
+}
+
+func (s *YAMLSuite) TestMarshal_OmitEmpty() {
+	c := config{
+		Name:    NewNull[string](),
+		Port:    New(8080),
+		Address: New(address{City: New("Paris")}),
+	}
+	b, err := yaml.Marshal(c)
+	s.Require().NoError(err)
+	s.Equal("name: null\nport: 8080\naddress:\n    city: Paris\nowner: null\n", string(b))
+}
+
+func (s *YAMLSuite) TestRoundTrip() {
+	in := config{
+		Name:    New("Alice"),
+		Port:    NewNull[int](),
+		Tags:    New([]string{"a", "b"}),
+		Address: New(address{City: NewNull[string]()}),
+		Limits:  map[string]Value[int]{"cpu": NewNull[int]()},
+		Meta:    Meta{Owner: New("ops")},
+	}
+	b, err := yaml.Marshal(in)
+	s.Require().NoError(err)
+
+	var out config
+	s.Require().NoError(Unmarshal(b, &out))
+	s.Equal(in, out)
+}
+
+func (s *YAMLSuite) TestDecodeNode() {
+	var node yaml.Node
+	s.Require().NoError(yaml.Unmarshal([]byte("name: ~"), &node))
+
+	var c config
+	s.Require().NoError(Decode(&node, &c))
+	s.True(c.Name.IsNull())
+}
+
+func (s *YAMLSuite) TestErrors() {
+	var c config
+	s.Error(Unmarshal([]byte("port: eighty"), &c))
+	s.Error(Unmarshal([]byte("name: [unclosed"), &c))
+	s.NoError(Unmarshal(nil, &c))
+}
+
+func (s *YAMLSuite) TestConstructors() {
+	p := "x"
+	s.Equal(New("x"), NewPtr(&p))
+	s.True(NewPtr[string](nil).IsNull())
+	s.Equal(New("x"), From(null.New("x")))
+}
@@ -0,0 +1,71 @@
+package null
+
+import (
+	"reflect"
+
+	"github.com/bjaus/null/internal/bridge"
+)
+
+// anyValue is the type-erased view of a Value[T]. It lets reflection-driven
+// code such as Apply inspect a Value without knowing T.
+//
+// Types that embed a Value (such as nullddb.Value) satisfy it through method
+// promotion.
+type anyValue interface {
+	State() State
+	elemType() reflect.Type
+	reflectValue() reflect.Value
+}
+
+// anySetter is implemented by *Value[T] and is the write side of anyValue.
+type anySetter interface {
+	setReflect(state State, x reflect.Value)
+}
+
+var (
+	anyValueType  = reflect.TypeFor[anyValue]()
+	anySetterType = reflect.TypeFor[anySetter]()
+)
+
+func (v Value[T]) elemType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// reflectValue returns the inner value with static type T, so interface
+// types keep their declared type rather than collapsing to the dynamic one.
+func (v Value[T]) reflectValue() reflect.Value {
+	return reflect.ValueOf(&v.v).Elem()
+}
+
+// setReflect stores x with the given state. x is only consulted for Valid
+// and must be assignable to T.
+func (v *Value[T]) setReflect(state State, x reflect.Value) {
+	*v = Value[T]{state: state}
+	if state == Valid {
+		reflect.ValueOf(&v.v).Elem().Set(x)
+	}
+}
+
+// isValueType reports whether t is a Value[T] (or embeds one) that can be
+// both read and written through reflection.
+func isValueType(t reflect.Type) bool {
+	return t.Implements(anyValueType) && reflect.PointerTo(t).Implements(anySetterType)
+}
+
+// valueElem returns T for a Value[T] type t.
+func valueElem(t reflect.Type) reflect.Type {
+	return reflect.Zero(t).Interface().(anyValue).elemType()
+}
+
+func init() {
+	bridge.IsValue = isValueType
+	bridge.Elem = valueElem
+	bridge.Set = func(v, x reflect.Value) {
+		setter := v.Addr().Interface().(anySetter)
+		if !x.IsValid() {
+			setter.setReflect(Null, x)
+			return
+		}
+		setter.setReflect(Valid, x)
+	}
+}
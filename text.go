@@ -0,0 +1,85 @@
+package null
+
+import (
+	"reflect"
+
+	"github.com/bjaus/null/internal/textconv"
+)
+
+// NullText is the text that MarshalText writes for Null and Unset Values and
+// that UnmarshalText reads as Null. A Valid string equal to NullText cannot
+// be represented. Empty text is not Null; use EmptyText for that, while
+// FlagVar, nullenv and nullform treat empty input as Null themselves.
+const NullText = "null"
+
+// MarshalText implements encoding.TextMarshaler.
+//
+// Valid values are encoded with T's own MarshalText if it has one, as
+// time.Duration.String for durations, and with strconv for strings, bools
+// and numbers (including named types of those kinds). []byte is written as
+// is. Null and Unset Values are written as NullText.
+func (v Value[T]) MarshalText() ([]byte, error) {
+	if v.state != Valid {
+		return []byte(NullText), nil
+	}
+	return textconv.Marshal(reflect.ValueOf(&v.v).Elem())
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+//
+// Text equal to NullText results in a Null Value. Anything else is decoded
+// with T's own UnmarshalText if *T has one, with time.ParseDuration for
+// durations, and with strconv for strings, bools and numbers (including
+// named types of those kinds), and results in a Valid Value.
+func (v *Value[T]) UnmarshalText(text []byte) error {
+	if string(text) == NullText {
+		*v = NewNull[T]()
+		return nil
+	}
+
+	var x T
+	if err := textconv.Unmarshal(reflect.ValueOf(&x).Elem(), text); err != nil {
+		return err
+	}
+	*v = New(x)
+	return nil
+}
+
+// --- Empty Text ---
+
+// EmptyText is a Value whose text form uses empty text for Null instead of
+// NullText, for configuration formats where an empty setting means "no
+// value". A Valid empty string cannot be represented, while "null" reads as
+// a Valid string. Every other method is Value's.
+//
+//	var limit null.EmptyText[int]
+//	limit.UnmarshalText([]byte(""))  // Null
+//	limit.UnmarshalText([]byte("5")) // Valid(5)
+type EmptyText[T any] struct {
+	Value[T]
+}
+
+// MarshalText implements encoding.TextMarshaler like Value.MarshalText,
+// but writes Null and Unset Values as empty text.
+func (v EmptyText[T]) MarshalText() ([]byte, error) {
+	if v.state != Valid {
+		return []byte{}, nil
+	}
+	return v.Value.MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler like
+// Value.UnmarshalText, but reads empty text as Null and NullText as T.
+func (v *EmptyText[T]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		v.Value = NewNull[T]()
+		return nil
+	}
+
+	var x T
+	if err := textconv.Unmarshal(reflect.ValueOf(&x).Elem(), text); err != nil {
+		return err
+	}
+	v.Value = New(x)
+	return nil
+}
@@ -0,0 +1,183 @@
+package null
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type textLevel int
+
+func TestMarshalText(t *testing.T) {
+	tests := map[string]struct {
+		v    encoding.TextMarshaler
+		want string
+	}{
+		"string":       {New("Alice"), "Alice"},
+		"empty string": {New(""), ""},
+		"bool":         {New(true), "true"},
+		"int":          {New(-42), "-42"},
+		"uint8":        {New[uint8](255), "255"},
+		"float32":      {New[float32](1.5), "1.5"},
+		"float64":      {New(0.1), "0.1"},
+		"named int":    {New(textLevel(3)), "3"},
+		"duration":     {New(90 * time.Second), "1m30s"},
+		"bytes":        {New([]byte("raw")), "raw"},
+		"time":         {New(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)), "2024-01-15T10:00:00Z"},
+		"marshaler":    {New(netip.MustParseAddr("10.0.0.1")), "10.0.0.1"},
+		"null":         {NewNull[int](), "null"},
+		"unset":        {Value[int]{}, "null"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tt.v.MarshalText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestUnmarshalText(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		v    interface {
+			encoding.TextUnmarshaler
+			anyValue
+		}
+		want    any
+		wantErr bool
+	}{
+		"string":       {"Alice", new(Value[string]), "Alice", false},
+		"empty string": {"", new(Value[string]), "", false},
+		"bool":         {"true", new(Value[bool]), true, false},
+		"int":          {"-42", new(Value[int]), -42, false},
+		"int8":         {"127", new(Value[int8]), int8(127), false},
+		"uint":         {"7", new(Value[uint]), uint(7), false},
+		"float64":      {"1.25", new(Value[float64]), 1.25, false},
+		"named int":    {"3", new(Value[textLevel]), textLevel(3), false},
+		"duration":     {"1m30s", new(Value[time.Duration]), 90 * time.Second, false},
+		"bytes":        {"raw", new(Value[[]byte]), []byte("raw"), false},
+		"unmarshaler":  {"10.0.0.1", new(Value[netip.Addr]), netip.MustParseAddr("10.0.0.1"), false},
+		"bad bool":     {"yes", new(Value[bool]), nil, true},
+		"bad int":      {"1.5", new(Value[int]), nil, true},
+		"overflow":     {"128", new(Value[int8]), nil, true},
+		"negative":     {"-1", new(Value[uint]), nil, true},
+		"bad duration": {"soon", new(Value[time.Duration]), nil, true},
+		"bad addr":     {"nope", new(Value[netip.Addr]), nil, true},
+		"unsupported":  {"x", new(Value[[]int]), nil, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.v.UnmarshalText([]byte(tt.text))
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, Unset, tt.v.State())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, Valid, tt.v.State())
+			assert.Equal(t, tt.want, tt.v.reflectValue().Interface())
+		})
+	}
+}
+
+type TextSuite struct {
+	suite.Suite
+}
+
+func TestTextSuite(t *testing.T) {
+	suite.Run(t, new(TextSuite))
+}
+
+func (s *TextSuite) TestNullLiteral() {
+	var v Value[int]
+	s.Require().NoError(v.UnmarshalText([]byte("null")))
+	s.True(v.IsNull())
+}
+
+func (s *TextSuite) TestEmptyIsNotNull() {
+	var v Value[string]
+	s.Require().NoError(v.UnmarshalText(nil))
+	s.Equal(New(""), v)
+
+	var n Value[int]
+	s.Error(n.UnmarshalText(nil))
+}
+
+func (s *TextSuite) TestMarshalUnsupported() {
+	_, err := New([]int{1}).MarshalText()
+	s.Error(err)
+}
+
+func (s *TextSuite) TestJSONMapKey() {
+	m := map[Value[string]]int{New("a"): 1, NewNull[string](): 2}
+	b, err := json.Marshal(m)
+	s.Require().NoError(err)
+	s.JSONEq(`{"a": 1, "null": 2}`, string(b))
+
+	var got map[Value[string]]int
+	s.Require().NoError(json.Unmarshal(b, &got))
+	s.Equal(m, got)
+}
+
+func (s *TextSuite) TestJSONStillPreferred() {
+	var v Value[string]
+	s.Require().NoError(json.Unmarshal([]byte(`"null"`), &v))
+	s.Equal(New("null"), v)
+}
+
+func (s *TextSuite) TestFlagTextVar() {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var port, retries Value[int]
+	fs.TextVar(&port, "port", Value[int]{}, "")
+	fs.TextVar(&retries, "retries", Value[int]{}, "")
+	s.Require().NoError(fs.Parse([]string{"-port", "8080", "-retries", "null"}))
+	s.Equal(New(8080), port)
+	s.True(retries.IsNull())
+}
+
+func (s *TextSuite) TestEmptyText() {
+	var v EmptyText[int]
+	s.Require().NoError(v.UnmarshalText(nil))
+	s.True(v.IsNull())
+	s.Require().NoError(v.UnmarshalText([]byte("5")))
+	s.Equal(New(5), v.Value)
+	s.Error(v.UnmarshalText([]byte("null")))
+
+	var str EmptyText[string]
+	s.Require().NoError(str.UnmarshalText([]byte("null")))
+	s.Equal(New("null"), str.Value)
+
+	tests := map[string]struct {
+		v    EmptyText[int]
+		want string
+	}{
+		"valid": {EmptyText[int]{New(5)}, "5"},
+		"null":  {EmptyText[int]{NewNull[int]()}, ""},
+		"unset": {EmptyText[int]{}, ""},
+	}
+	for name, tt := range tests {
+		s.Run(name, func() {
+			b, err := tt.v.MarshalText()
+			s.Require().NoError(err)
+			s.Equal(tt.want, string(b))
+		})
+	}
+}
+
+func (s *TextSuite) TestEmptyTextFlag() {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var limit, burst EmptyText[int]
+	fs.TextVar(&limit, "limit", EmptyText[int]{}, "")
+	fs.TextVar(&burst, "burst", EmptyText[int]{}, "")
+	s.Require().NoError(fs.Parse([]string{"-limit=", "-burst", "3"}))
+	s.True(limit.IsNull())
+	s.Equal(New(3), burst.Value)
+}
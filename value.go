@@ -91,6 +91,13 @@ func (v Value[T]) State() State {
 	return v.state
 }
 
+// IsZero reports whether the Value is Unset.
+// It lets the `omitzero` JSON option drop exactly the fields that were
+// never set, while Null fields are still written as null.
+func (v Value[T]) IsZero() bool {
+	return v.state == Unset
+}
+
 // --- Value Extraction ---
 
 // Get returns the underlying value.
@@ -125,7 +132,8 @@ var nullBytes = []byte("null")
 
 // MarshalJSON implements json.Marshaler.
 // Valid values are marshaled as their JSON representation.
-// Null and unset values are marshaled as null.
+// Null and unset values are marshaled as null; tag fields with `omitzero`
+// to omit unset values instead.
 func (v Value[T]) MarshalJSON() ([]byte, error) {
 	if v.state == Valid {
 		return json.Marshal(v.v)
@@ -210,6 +210,42 @@ func (s *JSONSuite) TestMarshal() {
 	s.Equal(`{"name":"Alice","email":null,"age":null}`, string(data))
 }
 
+func (s *JSONSuite) TestMarshal_OmitZero() {
+	type Patch struct {
+		Name  Value[string] `json:"name,omitzero"`
+		Email Value[string] `json:"email,omitzero"`
+		Age   Value[int]    `json:"age,omitzero"`
+	}
+
+	tests := map[string]struct {
+		patch Patch
+		want  string
+	}{
+		"all unset": {Patch{}, `{}`},
+		"null kept": {Patch{Email: NewNull[string]()}, `{"email":null}`},
+		"zero kept": {Patch{Age: New(0)}, `{"age":0}`},
+		"mixed":     {Patch{Name: New("Alice"), Email: NewNull[string]()}, `{"name":"Alice","email":null}`},
+	}
+	for name, tt := range tests {
+		s.Run(name, func() {
+			data, err := json.Marshal(tt.patch)
+			s.Require().NoError(err)
+			s.Equal(tt.want, string(data))
+
+			var got Patch
+			s.Require().NoError(json.Unmarshal(data, &got))
+			s.Equal(tt.patch, got)
+		})
+	}
+}
+
+func (s *JSONSuite) TestIsZero() {
+	var u Value[string]
+	s.True(u.IsZero())
+	s.False(NewNull[string]().IsZero())
+	s.False(New("").IsZero())
+}
+
 func (s *JSONSuite) TestUnmarshal_InvalidType() {
 	var v Value[int]
 	err := json.Unmarshal([]byte(`"not a number"`), &v)
@@ -0,0 +1,96 @@
+package null
+
+import "encoding/xml"
+
+// xsiNamespace is the XML Schema instance namespace that defines xsi:nil.
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// MarshalXML implements xml.Marshaler.
+// Valid values are encoded as T would be. Null values are written as an
+// empty element with xsi:nil="true", declaring the xsi prefix on the
+// element itself. Unset values write nothing, so the element is omitted.
+func (v Value[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	switch v.state {
+	case Valid:
+		return e.EncodeElement(v.v, start)
+	case Null:
+		// encoding/xml cannot choose the prefix of a namespaced attribute:
+		// Name{Space: xsiNamespace, Local: "nil"} would be written as
+		// XMLSchema-instance:nil. Writing the declaration and the prefixed
+		// name as plain attributes gives the conventional xsi:nil, which
+		// namespace-aware parsers resolve to the same name.
+		start.Attr = append(start.Attr,
+			xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: xsiNamespace},
+			xml.Attr{Name: xml.Name{Local: "xsi:nil"}, Value: "true"},
+		)
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+	default:
+		return nil
+	}
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+// An element with xsi:nil="true" results in a Null Value; any other element
+// is decoded into T and results in a Valid Value. Absent elements leave the
+// Value Unset. Every repeated element replaces the Value, so decode lists
+// into []Value[T] rather than Value[[]T].
+func (v *Value[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if isXSINil(start.Attr) {
+		*v = NewNull[T]()
+		return d.Skip()
+	}
+	var x T
+	if err := d.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+	*v = New(x)
+	return nil
+}
+
+// isXSINil reports whether attrs contain xsi:nil="true". The prefix is
+// matched even when the document does not declare it.
+func isXSINil(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Local == "nil" && (a.Name.Space == xsiNamespace || a.Name.Space == "xsi") {
+			return a.Value == "true" || a.Value == "1"
+		}
+	}
+	return false
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr.
+// Unset values omit the attribute. Valid values use T's MarshalXMLAttr if
+// it has one; otherwise the attribute holds MarshalText, so Null values are
+// written as NullText.
+func (v Value[T]) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if v.state == Unset {
+		return xml.Attr{}, nil
+	}
+	if m, ok := any(v.v).(xml.MarshalerAttr); ok && v.state == Valid {
+		return m.MarshalXMLAttr(name)
+	}
+	text, err := v.MarshalText()
+	if err != nil {
+		return xml.Attr{}, err
+	}
+	return xml.Attr{Name: name, Value: string(text)}, nil
+}
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+// The attribute is decoded with T's UnmarshalXMLAttr if it has one, and
+// with UnmarshalText (so NullText results in Null) otherwise. Absent
+// attributes leave the Value Unset.
+func (v *Value[T]) UnmarshalXMLAttr(attr xml.Attr) error {
+	var x T
+	if u, ok := any(&x).(xml.UnmarshalerAttr); ok && attr.Value != NullText {
+		if err := u.UnmarshalXMLAttr(attr); err != nil {
+			return err
+		}
+		*v = New(x)
+		return nil
+	}
+	return v.UnmarshalText([]byte(attr.Value))
+}
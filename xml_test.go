@@ -0,0 +1,203 @@
+package null
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type xmlAddress struct {
+	City   string `xml:"city"`
+	Street string `xml:"street,omitempty"`
+}
+
+type xmlUser struct {
+	XMLName xml.Name          `xml:"user"`
+	ID      Value[int]        `xml:"id,attr"`
+	Role    Value[string]     `xml:"role,attr"`
+	Name    Value[string]     `xml:"name"`
+	Email   Value[string]     `xml:"email"`
+	Age     Value[int]        `xml:"age"`
+	Address Value[xmlAddress] `xml:"address"`
+	Tags    Value[[]string]   `xml:"tag"`
+}
+
+func TestMarshalXML(t *testing.T) {
+	const nilAttrs = `xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"`
+	tests := map[string]struct {
+		u    xmlUser
+		want string
+	}{
+		"unset omitted": {
+			xmlUser{},
+			`<user></user>`,
+		},
+		"valid": {
+			xmlUser{Name: New("Alice"), Age: New(30)},
+			`<user><name>Alice</name><age>30</age></user>`,
+		},
+		"null": {
+			xmlUser{Email: NewNull[string]()},
+			`<user><email ` + nilAttrs + `></email></user>`,
+		},
+		"empty string": {
+			xmlUser{Name: New("")},
+			`<user><name></name></user>`,
+		},
+		"struct": {
+			xmlUser{Address: New(xmlAddress{City: "Paris"})},
+			`<user><address><city>Paris</city></address></user>`,
+		},
+		"slice": {
+			xmlUser{Tags: New([]string{"a", "b"})},
+			`<user><tag>a</tag><tag>b</tag></user>`,
+		},
+		"attributes": {
+			xmlUser{ID: New(7), Role: NewNull[string]()},
+			`<user id="7" role="null"></user>`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, err := xml.Marshal(tt.u)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(b))
+		})
+	}
+}
+
+func TestMarshalXML_NamespaceAwareDecoder(t *testing.T) {
+	b, err := xml.Marshal(xmlUser{Email: NewNull[string]()})
+	require.NoError(t, err)
+
+	// The decoder resolves the xsi prefix through the declaration.
+	d := xml.NewDecoder(strings.NewReader(string(b)))
+	var attrs []xml.Attr
+	for {
+		tok, err := d.Token()
+		require.NoError(t, err)
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "email" {
+			attrs = se.Attr
+			break
+		}
+	}
+	assert.Contains(t, attrs, xml.Attr{Name: xml.Name{Space: xsiNamespace, Local: "nil"}, Value: "true"})
+	assert.Contains(t, attrs, xml.Attr{Name: xml.Name{Space: "xmlns", Local: "xsi"}, Value: xsiNamespace})
+}
+
+type XMLSuite struct {
+	suite.Suite
+}
+
+func TestXMLSuite(t *testing.T) {
+	suite.Run(t, new(XMLSuite))
+}
+
+func (s *XMLSuite) unmarshal(doc string) xmlUser {
+	var u xmlUser
+	s.Require().NoError(xml.Unmarshal([]byte(doc), &u))
+	return u
+}
+
+func (s *XMLSuite) TestUnmarshal_ThreeStates() {
+	u := s.unmarshal(`<user xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+		<name>Alice</name>
+		<email xsi:nil="true"/>
+	</user>`)
+	s.Equal(New("Alice"), u.Name)
+	s.True(u.Email.IsNull())
+	s.False(u.Age.IsSet())
+}
+
+func (s *XMLSuite) TestUnmarshal_UndeclaredPrefix() {
+	u := s.unmarshal(`<user><email xsi:nil="1"></email></user>`)
+	s.True(u.Email.IsNull())
+}
+
+func (s *XMLSuite) TestUnmarshal_NilFalse() {
+	u := s.unmarshal(`<user xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><name xsi:nil="false">x</name></user>`)
+	s.Equal(New("x"), u.Name)
+}
+
+func (s *XMLSuite) TestUnmarshal_Nested() {
+	u := s.unmarshal(`<user><address><city>Paris</city></address><tag>a</tag><tag>b</tag></user>`)
+	s.Equal(New(xmlAddress{City: "Paris"}), u.Address)
+	s.Equal(New([]string{"b"}), u.Tags, "each element replaces the Value")
+}
+
+func (s *XMLSuite) TestUnmarshal_Attributes() {
+	u := s.unmarshal(`<user id="7" role="null"></user>`)
+	s.Equal(New(7), u.ID)
+	s.True(u.Role.IsNull())
+
+	u = s.unmarshal(`<user></user>`)
+	s.False(u.ID.IsSet())
+	s.False(u.Role.IsSet())
+}
+
+func (s *XMLSuite) TestUnmarshal_Errors() {
+	var u xmlUser
+	s.Error(xml.Unmarshal([]byte(`<user><age>old</age></user>`), &u))
+	s.Error(xml.Unmarshal([]byte(`<user id="x"></user>`), &u))
+}
+
+func (s *XMLSuite) TestRoundTrip() {
+	in := xmlUser{
+		XMLName: xml.Name{Local: "user"},
+		ID:      New(1),
+		Name:    New("Alice"),
+		Email:   NewNull[string](),
+		Address: New(xmlAddress{City: "Paris", Street: "Main"}),
+	}
+	b, err := xml.Marshal(in)
+	s.Require().NoError(err)
+
+	var out xmlUser
+	s.Require().NoError(xml.Unmarshal(b, &out))
+	s.Equal(in, out)
+}
+
+func (s *XMLSuite) TestAttrTextTypes() {
+	type event struct {
+		At  Value[time.Time]     `xml:"at,attr"`
+		TTL Value[time.Duration] `xml:"ttl,attr"`
+	}
+	at := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	b, err := xml.Marshal(event{At: New(at), TTL: New(time.Minute)})
+	s.Require().NoError(err)
+	s.Equal(`<event at="2024-01-15T10:00:00Z" ttl="1m0s"></event>`, string(b))
+
+	var e event
+	s.Require().NoError(xml.NewDecoder(strings.NewReader(string(b))).Decode(&e))
+	s.Equal(New(at), e.At)
+	s.Equal(New(time.Minute), e.TTL)
+}
+
+type xmlAttrName string
+
+func (n xmlAttrName) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: strings.ToUpper(string(n))}, nil
+}
+
+func (n *xmlAttrName) UnmarshalXMLAttr(attr xml.Attr) error {
+	*n = xmlAttrName(strings.ToLower(attr.Value))
+	return nil
+}
+
+func (s *XMLSuite) TestAttrDelegates() {
+	type tagged struct {
+		Name Value[xmlAttrName] `xml:"name,attr"`
+	}
+	b, err := xml.Marshal(tagged{Name: New(xmlAttrName("alice"))})
+	s.Require().NoError(err)
+	s.Equal(`<tagged name="ALICE"></tagged>`, string(b))
+
+	var t tagged
+	s.Require().NoError(xml.Unmarshal(b, &t))
+	s.Equal(New(xmlAttrName("alice")), t.Name)
+}